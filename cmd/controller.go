@@ -0,0 +1,113 @@
+/*
+Copyright © 2024 Shuvojit Sarkar <s15sarkar@yahoo.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/internal/utils"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/distributed"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/webhook_tester"
+	"github.com/spf13/cobra"
+)
+
+func runController(configPath string, listen string, workers int) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		utils.PPrinter.Error("Failed due to: ", err.Error())
+		os.Exit(1)
+	}
+	utils.PPrinter.Info("Config loaded successfully...")
+
+	wt := webhook_tester.NewDefaultWebhookTester(config)
+	if err = wt.LoadConfig(); err != nil {
+		utils.PPrinter.Error("Failed to load config due to: %v", err.Error())
+		os.Exit(1)
+	}
+
+	utils.PPrinter.Info("Started receiver...")
+	cancelReciever, err := wt.StartReceiver()
+	defer cancelReciever()
+	if err != nil {
+		utils.PPrinter.Error(
+			fmt.Sprintf("Failed to start receiver: %v", err.Error()),
+		)
+		os.Exit(1)
+	}
+
+	controller, err := distributed.NewController(config, wt, workers)
+	if err != nil {
+		utils.PPrinter.Error(fmt.Sprintf("Failed to plan run: %v", err))
+		os.Exit(1)
+	}
+
+	server := &http.Server{Addr: listen, Handler: controller.Handler()}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			utils.PPrinter.Error(fmt.Sprintf("Controller stopped: %v", err))
+			os.Exit(1)
+		}
+	}()
+	defer server.Shutdown(context.Background())
+
+	utils.PPrinter.Info(fmt.Sprintf("Waiting for %d workers on %s...", workers, listen))
+	<-controller.Ready()
+
+	utils.PPrinter.Info("All workers joined, firing requests...")
+	workersTimeout := time.Duration(config.Run.DurationSeconds+config.Test.Timeout) * time.Second
+	if err := controller.WaitForWorkers(workersTimeout); err != nil {
+		utils.PPrinter.Warning("Timed out waiting for records from every worker")
+	}
+
+	utils.PPrinter.Info(fmt.Sprintf("Waiting for responses for %ds...", config.Test.Timeout))
+	if err := wt.WaitForResults(); err != nil {
+		utils.PPrinter.Warning(fmt.Sprintf("Timed out waiting for %ds", config.Test.Timeout))
+	} else {
+		utils.PPrinter.Success("Received webhook responses within timeout.")
+	}
+
+	utils.PPrinter.Info("Starting post processing...")
+	if err := wt.PostProcess(); err != nil {
+		utils.PPrinter.Error(fmt.Sprintf("Failed to post process: %v", err))
+	} else {
+		utils.PPrinter.Success("Post processing complete.")
+	}
+}
+
+// controllerCmd represents the controller command
+var controllerCmd = &cobra.Command{
+	Use:   "controller",
+	Short: "Coordinate a test run across several workers",
+	Long: `The controller command splits a test configuration between several workers.
+
+The controller runs the webhook receiver, waits for the given number of workers
+to join, hands each of them a slice of the load plan and merges the records
+they ship back into a single report.
+
+Usage:
+  webhook-load-tester controller --config <path-to-config-file.yaml> --workers <n>
+
+Example:
+  webhook-load-tester controller --config ./tests/payment-api-test.yaml --workers 3 --listen :7070`,
+	PreRunE: setupVerboseLogger,
+	Run: func(cmd *cobra.Command, args []string) {
+		configPath, _ := cmd.Flags().GetString("config")
+		listen, _ := cmd.Flags().GetString("listen")
+		workers, _ := cmd.Flags().GetInt("workers")
+		runController(configPath, listen, workers)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(controllerCmd)
+
+	controllerCmd.Flags().StringP("config", "c", "wlt.yaml", "Path to the test config")
+	controllerCmd.Flags().StringP("listen", "l", ":7070", "Address workers connect to")
+	controllerCmd.Flags().IntP("workers", "w", 1, "Number of workers to wait for")
+	controllerCmd.MarkFlagRequired("config")
+}
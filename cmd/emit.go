@@ -0,0 +1,77 @@
+/*
+Copyright © 2024 Shuvojit Sarkar <s15sarkar@yahoo.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/internal/utils"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/consumer"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/webhook_tester"
+	"github.com/spf13/cobra"
+)
+
+func runEmit(configPath string, target string) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		utils.PPrinter.Error("Failed due to: ", err.Error())
+		os.Exit(1)
+	}
+	if target != "" {
+		config.Emit.Target = target
+	}
+	if len(config.Outputs) == 0 {
+		config.Outputs = []types.OutputConfig{{Type: "stdout"}}
+	}
+	utils.PPrinter.Info("Config loaded successfully...")
+
+	emitter, err := consumer.NewEmitter(config)
+	if err != nil {
+		utils.PPrinter.Error(fmt.Sprintf("Failed to load config due to: %v", err))
+		os.Exit(1)
+	}
+
+	utils.PPrinter.Info("Sending webhooks to " + config.Emit.Target + "...")
+	metrics := emitter.Run()
+
+	utils.PPrinter.Info("Starting post processing...")
+	report := reporter.Report{Metrics: metrics, Records: emitter.Records(), Config: config}
+	if err := webhook_tester.WriteOutputs(config.Outputs, report); err != nil {
+		utils.PPrinter.Error(fmt.Sprintf("Failed to post process: %v", err))
+	} else {
+		utils.PPrinter.Success("Post processing complete.")
+	}
+}
+
+// emitCmd represents the emit command
+var emitCmd = &cobra.Command{
+	Use:   "emit",
+	Short: "Send synthesized webhooks to a webhook consumer",
+	Long: `The emit command plays the webhook provider. It renders webhooks from the
+templates in the emit section of the config and sends them to a consumer at
+the load described by the run section, reporting latency and error rates.
+
+Usage:
+  webhook-load-tester emit --config <path-to-config-file.yaml>
+
+Example:
+  webhook-load-tester emit --config ./tests/billing-consumer.yaml --target http://localhost:9000/webhooks`,
+	PreRunE: setupVerboseLogger,
+	Run: func(cmd *cobra.Command, args []string) {
+		configPath, _ := cmd.Flags().GetString("config")
+		target, _ := cmd.Flags().GetString("target")
+		runEmit(configPath, target)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(emitCmd)
+
+	emitCmd.Flags().StringP("config", "c", "wlt.yaml", "Path to the emit config")
+	emitCmd.Flags().StringP("target", "t", "", "Url of the webhook consumer, overrides emit.target")
+	emitCmd.MarkFlagRequired("config")
+}
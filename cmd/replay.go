@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Shuvojit Sarkar <s15sarkar@yahoo.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/internal/utils"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/consumer"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/webhook_tester"
+	"github.com/spf13/cobra"
+)
+
+func runReplay(cmd *cobra.Command) {
+	config := &types.InputConfig{}
+	if configPath, _ := cmd.Flags().GetString("config"); configPath != "" {
+		loaded, err := loadConfig(configPath)
+		if err != nil {
+			utils.PPrinter.Error("Failed due to: ", err.Error())
+			os.Exit(1)
+		}
+		config = loaded
+	}
+
+	// flags win over the config file
+	flags := cmd.Flags()
+	if flags.Changed("archive") {
+		config.Replay.Archive, _ = flags.GetString("archive")
+	}
+	if flags.Changed("target") {
+		config.Replay.Target, _ = flags.GetString("target")
+	}
+	if flags.Changed("rate") {
+		config.Replay.Rate, _ = flags.GetFloat64("rate")
+	}
+	if flags.Changed("speed") {
+		config.Replay.Speed, _ = flags.GetFloat64("speed")
+	}
+	if len(config.Outputs) == 0 {
+		config.Outputs = []types.OutputConfig{{Type: "stdout"}}
+	}
+
+	replayer, err := consumer.NewReplayer(config)
+	if err != nil {
+		utils.PPrinter.Error(fmt.Sprintf("Failed to load replay: %v", err))
+		os.Exit(1)
+	}
+
+	utils.PPrinter.Info("Replaying callbacks to " + config.Replay.Target + "...")
+	metrics := replayer.Run()
+
+	utils.PPrinter.Info("Starting post processing...")
+	report := reporter.Report{Metrics: metrics, Records: replayer.Records(), Config: config}
+	if err := webhook_tester.WriteOutputs(config.Outputs, report); err != nil {
+		utils.PPrinter.Error(fmt.Sprintf("Failed to post process: %v", err))
+	} else {
+		utils.PPrinter.Success("Post processing complete.")
+	}
+}
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-send archived callbacks to a webhook consumer",
+	Long: `The replay command sends the callbacks captured in an archive to a webhook
+consumer and reports how fast, and with which status codes, it answered.
+
+Callbacks are sent with their original pacing unless a rate or a speed-up
+factor is given. Signatures can be refreshed through the replay.sign section
+of the config.
+
+Usage:
+  webhook-load-tester replay --archive <archive.jsonl|archive.har> --target <url>
+
+Example:
+  webhook-load-tester replay --archive out/exchanges.jsonl --target http://localhost:9000/webhooks --speed 4`,
+	PreRunE: setupVerboseLogger,
+	Run: func(cmd *cobra.Command, args []string) {
+		runReplay(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().StringP("config", "c", "", "Path to a config with a replay section")
+	replayCmd.Flags().StringP("archive", "a", "", "Archive to replay callbacks from")
+	replayCmd.Flags().StringP("target", "t", "", "Url of the webhook consumer")
+	replayCmd.Flags().Float64("rate", 0, "Callbacks per second, instead of the original pacing")
+	replayCmd.Flags().Float64("speed", 0, "Speed-up factor applied to the original pacing")
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2024 Shuvojit Sarkar <s15sarkar@yahoo.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/internal/utils"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/webhook_tester"
+	"github.com/spf13/cobra"
+)
+
+func runReport(cmd *cobra.Command) {
+	storePath, _ := cmd.Flags().GetString("store")
+
+	var outputs []types.OutputConfig
+	if outputType, _ := cmd.Flags().GetString("output"); outputType != "" {
+		outputPath, _ := cmd.Flags().GetString("path")
+		outputs = append(outputs, types.OutputConfig{Type: outputType, Path: outputPath})
+	}
+
+	utils.PPrinter.Info("Reporting on " + storePath + "...")
+	if err := webhook_tester.ReportStore(storePath, outputs); err != nil {
+		utils.PPrinter.Error(fmt.Sprintf("Failed to report: %v", err))
+		os.Exit(1)
+	}
+	utils.PPrinter.Success("Post processing complete.")
+}
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report on the records a run left in a tracker store",
+	Long: `The report command calculates the metrics of a run from the bolt tracker
+store it wrote, without running anything. Use it when a long run crashed or
+was killed before post processing.
+
+The outputs of the recorded config are written unless one is given.
+
+Usage:
+  webhook-load-tester report --store <run.db> [--output text --path <report.txt>]
+
+Example:
+  webhook-load-tester report --store out/soak.db --output stdout`,
+	PreRunE: setupVerboseLogger,
+	Run: func(cmd *cobra.Command, args []string) {
+		runReport(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringP("store", "s", "", "Tracker store written by the run")
+	reportCmd.Flags().StringP("output", "o", "", "Output type, instead of the recorded outputs")
+	reportCmd.Flags().StringP("path", "p", "", "Path of the output, for file outputs")
+	reportCmd.MarkFlagRequired("store")
+}
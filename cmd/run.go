@@ -30,6 +30,16 @@ func setupLogger(isVerbose bool) {
 	}
 }
 
+func setupVerboseLogger(cmd *cobra.Command, args []string) error {
+	isVerbose, err := rootCmd.PersistentFlags().GetBool("verbose")
+	if err != nil {
+		return err
+	}
+	// FIXME: should run in rootCmd not in every cmd
+	setupLogger(isVerbose)
+	return nil
+}
+
 var DEFAULT_WAITING_TIMEOUT = time.Duration(30) * time.Second
 
 func setDefaults(config *types.InputConfig) {
@@ -121,15 +131,7 @@ Usage:
 
 Example:
   webhook-load-tester run --config ./tests/payment-api-test.yaml`,
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		isVerbose, err := rootCmd.PersistentFlags().GetBool("verbose")
-		if err != nil {
-			return err
-		}
-		// FIXME: should run in rootCmd not run cmd
-		setupLogger(isVerbose)
-		return nil
-	},
+	PreRunE: setupVerboseLogger,
 	Run: func(cmd *cobra.Command, args []string) {
 		configPath, _ := cmd.Flags().GetString("config")
 		runTest(configPath)
@@ -0,0 +1,51 @@
+/*
+Copyright © 2024 Shuvojit Sarkar <s15sarkar@yahoo.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/internal/utils"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/distributed"
+	"github.com/spf13/cobra"
+)
+
+// workerCmd represents the worker command
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Fire a share of the load for a controller",
+	Long: `The worker command joins a controller, fires the slice of the load plan it is
+assigned and ships its records back to the controller for reporting.
+
+Workers need no config file of their own, everything is sent by the controller.
+
+Usage:
+  webhook-load-tester worker --controller <controller-url>
+
+Example:
+  webhook-load-tester worker --controller http://10.0.0.5:7070`,
+	PreRunE: setupVerboseLogger,
+	Run: func(cmd *cobra.Command, args []string) {
+		controllerURL, _ := cmd.Flags().GetString("controller")
+		name, _ := cmd.Flags().GetString("name")
+
+		utils.PPrinter.Info("Joining controller at " + controllerURL + "...")
+		if err := distributed.RunWorker(context.Background(), controllerURL, name); err != nil {
+			utils.PPrinter.Error(fmt.Sprintf("Worker failed: %v", err))
+			os.Exit(1)
+		}
+		utils.PPrinter.Success("Requests fired and records shipped to controller.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+
+	hostname, _ := os.Hostname()
+	workerCmd.Flags().String("controller", "http://localhost:7070", "Url of the controller")
+	workerCmd.Flags().String("name", hostname, "Name reported to the controller")
+	workerCmd.MarkFlagRequired("controller")
+}
@@ -0,0 +1,183 @@
+// Package archive captures every exchange of a run, trigger requests and
+// received callbacks alike, so that single requests can be inspected once
+// the run is over.
+package archive
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	FormatJSONL = "jsonl"
+	FormatHAR   = "har"
+)
+
+const (
+	KindTrigger  = "trigger"
+	KindCallback = "callback"
+)
+
+// Redacted replaces the value of redacted headers.
+const Redacted = "[REDACTED]"
+
+// defaultRedactions are always applied on top of the configured ones.
+var defaultRedactions = []string{"authorization", "proxy-authorization", "cookie", "set-cookie"}
+
+// Message is one side of an exchange.
+type Message struct {
+	Method  string              `json:"method,omitempty"`
+	URL     string              `json:"url,omitempty"`
+	Status  int                 `json:"status,omitempty"`
+	Headers map[string][]string `json:"headers"`
+	Body    string              `json:"body"`
+	// BodySize is the size of the body before it was capped
+	BodySize  int  `json:"bodySize"`
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Entry is a single exchange, either a trigger request sent to the api under
+// test or a callback received from it.
+type Entry struct {
+	Kind          string    `json:"kind"`
+	CorrelationID string    `json:"correlationId,omitempty"`
+	StartedAt     time.Time `json:"startedAt"`
+	FinishedAt    time.Time `json:"finishedAt"`
+	Request       Message   `json:"request"`
+	Response      *Message  `json:"response,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	OrphanReason  string    `json:"orphanReason,omitempty"`
+}
+
+// encoder writes entries in a given file format.
+type encoder interface {
+	encode(w *bufio.Writer, e Entry) error
+	close(w *bufio.Writer) error
+}
+
+// Archive writes entries to a file as they happen. It is safe for
+// concurrent use.
+type Archive struct {
+	lock         sync.Mutex
+	file         *os.File
+	w            *bufio.Writer
+	enc          encoder
+	maxBodyBytes int
+	redact       []string
+	closed       bool
+}
+
+// New opens the archive described by cfg. A nil archive is returned when no
+// path is configured.
+func New(cfg types.ArchiveConfig) (*Archive, error) {
+	if cfg.Path == "" {
+		return nil, nil
+	}
+
+	var enc encoder
+	switch cfg.Format {
+	case "", FormatJSONL:
+		enc = &jsonlEncoder{}
+	case FormatHAR:
+		enc = &harEncoder{}
+	default:
+		return nil, errors.New("Unknown archive format: " + cfg.Format)
+	}
+
+	redact := append([]string{}, defaultRedactions...)
+	for _, pattern := range cfg.RedactHeaders {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.New("Invalid header redaction pattern: " + pattern)
+		}
+		redact = append(redact, pattern)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Archive{
+		file:         f,
+		w:            bufio.NewWriter(f),
+		enc:          enc,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		redact:       redact,
+	}, nil
+}
+
+// NewMessage captures headers and body, applying the archive's redaction
+// rules and body cap.
+func (a *Archive) NewMessage(header http.Header, body []byte) Message {
+	m := Message{
+		Headers:  make(map[string][]string, len(header)),
+		BodySize: len(body),
+	}
+	for name, values := range header {
+		if a.redacts(name) {
+			m.Headers[name] = []string{Redacted}
+		} else {
+			m.Headers[name] = values
+		}
+	}
+
+	if a.maxBodyBytes > 0 && len(body) > a.maxBodyBytes {
+		body = body[:a.maxBodyBytes]
+		m.Truncated = true
+	}
+	m.Body = string(body)
+	return m
+}
+
+func (a *Archive) redacts(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range a.redact {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Record appends e to the archive. Entries recorded after Close are dropped.
+func (a *Archive) Record(e Entry) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	return a.enc.encode(a.w, e)
+}
+
+// Close finishes the file, HAR archives are only valid once closed.
+func (a *Archive) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	if err := a.enc.close(a.w); err != nil {
+		return err
+	}
+	if err := a.w.Flush(); err != nil {
+		return err
+	}
+	return a.file.Close()
+}
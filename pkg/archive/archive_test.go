@@ -0,0 +1,135 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func sampleEntry(a *Archive) Entry {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer token")
+	header.Set("X-Client-Secret", "s3cr3t")
+	header.Set("Content-Type", "application/json")
+
+	request := a.NewMessage(header, []byte(`{"id":"0123456789"}`))
+	request.Method = http.MethodPost
+	request.URL = "http://localhost:8080/trigger"
+	response := a.NewMessage(http.Header{}, []byte("ok"))
+	response.Status = http.StatusAccepted
+
+	now := time.Now()
+	return Entry{
+		Kind:          KindTrigger,
+		CorrelationID: "abc",
+		StartedAt:     now,
+		FinishedAt:    now.Add(10 * time.Millisecond),
+		Request:       request,
+		Response:      &response,
+	}
+}
+
+func TestJSONLRedactsAndCaps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	a, err := New(types.ArchiveConfig{Path: path, MaxBodyBytes: 8, RedactHeaders: []string{"*-secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Record(sampleEntry(a))
+	a.Record(sampleEntry(a))
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		headers := http.Header(e.Request.Headers)
+		if got := headers.Get("Authorization"); got != Redacted {
+			t.Errorf("Authorization = %q, want it redacted", got)
+		}
+		if got := headers.Get("X-Client-Secret"); got != Redacted {
+			t.Errorf("X-Client-Secret = %q, want it redacted", got)
+		}
+		if got := headers.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want it kept", got)
+		}
+		if e.Request.Body != `{"id":"0` || !e.Request.Truncated || e.Request.BodySize != 19 {
+			t.Errorf("body = %q (truncated %v, size %d), want it capped at 8 bytes", e.Request.Body, e.Request.Truncated, e.Request.BodySize)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("got %d lines, want 2", lines)
+	}
+}
+
+func TestHARIsValid(t *testing.T) {
+	for _, entries := range []int{0, 1, 3} {
+		path := filepath.Join(t.TempDir(), "archive.har")
+		a, err := New(types.ArchiveConfig{Path: path, Format: FormatHAR})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < entries; i++ {
+			a.Record(sampleEntry(a))
+		}
+		a.Close()
+		// late entries are dropped rather than corrupting the file
+		a.Record(sampleEntry(a))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var har struct {
+			Log struct {
+				Version string
+				Entries []harEntry
+			}
+		}
+		if err := json.Unmarshal(data, &har); err != nil {
+			t.Fatalf("invalid HAR with %d entries: %v", entries, err)
+		}
+		if len(har.Log.Entries) != entries {
+			t.Errorf("got %d entries, want %d", len(har.Log.Entries), entries)
+		}
+		for _, e := range har.Log.Entries {
+			if e.Response.Status != http.StatusAccepted || e.CorrelationID != "abc" {
+				t.Errorf("unexpected entry %+v", e)
+			}
+		}
+
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range loaded {
+			if e.Request.Body != `{"id":"0123456789"}` || e.Response.Status != http.StatusAccepted {
+				t.Errorf("entry not read back from HAR: %+v", e)
+			}
+		}
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := New(types.ArchiveConfig{Path: filepath.Join(t.TempDir(), "a"), Format: "xml"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
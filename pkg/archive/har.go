@@ -0,0 +1,169 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// harEncoder streams entries into a HAR 1.2 log. Webhook specific data is
+// kept in underscore prefixed fields, as allowed by the spec.
+type harEncoder struct {
+	started bool
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type harEntry struct {
+	StartedDateTime string         `json:"startedDateTime"`
+	Time            float64        `json:"time"`
+	Request         harRequest     `json:"request"`
+	Response        harResponse    `json:"response"`
+	Cache           map[string]any `json:"cache"`
+	Timings         harTimings     `json:"timings"`
+	Kind            string         `json:"_kind"`
+	CorrelationID   string         `json:"_correlationId,omitempty"`
+	Error           string         `json:"_error,omitempty"`
+	OrphanReason    string         `json:"_orphanReason,omitempty"`
+	Truncated       bool           `json:"_truncated,omitempty"`
+}
+
+const harHeader = `{"log":{"version":"1.2","creator":{"name":"webhook-load-tester","version":"v1"},"entries":[`
+
+func (h *harEncoder) encode(w *bufio.Writer, e Entry) error {
+	if !h.started {
+		w.WriteString(harHeader + "\n")
+		h.started = true
+	} else {
+		w.WriteString(",\n")
+	}
+
+	payload, err := json.Marshal(toHAR(e))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(payload)
+	return err
+}
+
+func (h *harEncoder) close(w *bufio.Writer) error {
+	if !h.started {
+		w.WriteString(harHeader)
+	}
+	_, err := w.WriteString("\n]}}\n")
+	return err
+}
+
+func toHAR(e Entry) harEntry {
+	elapsed := float64(e.FinishedAt.Sub(e.StartedAt)) / float64(time.Millisecond)
+	entry := harEntry{
+		StartedDateTime: e.StartedAt.Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request: harRequest{
+			Method:      e.Request.Method,
+			URL:         e.Request.URL,
+			HTTPVersion: "HTTP/1.1",
+			Headers:     harHeaders(e.Request.Headers),
+			QueryString: []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    e.Request.BodySize,
+			PostData: &harPostData{
+				MimeType: firstHeader(e.Request.Headers, "Content-Type"),
+				Text:     e.Request.Body,
+			},
+		},
+		Response: harResponse{
+			HTTPVersion: "HTTP/1.1",
+			Headers:     []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Cache:         map[string]any{},
+		Timings:       harTimings{Send: 0, Wait: elapsed, Receive: 0},
+		Kind:          e.Kind,
+		CorrelationID: e.CorrelationID,
+		Error:         e.Error,
+		OrphanReason:  e.OrphanReason,
+		Truncated:     e.Request.Truncated,
+	}
+
+	if e.Response != nil {
+		entry.Response.Status = e.Response.Status
+		entry.Response.StatusText = http.StatusText(e.Response.Status)
+		entry.Response.Headers = harHeaders(e.Response.Headers)
+		entry.Response.BodySize = e.Response.BodySize
+		entry.Response.Content = harContent{
+			Size:     e.Response.BodySize,
+			MimeType: firstHeader(e.Response.Headers, "Content-Type"),
+			Text:     e.Response.Body,
+		}
+		entry.Truncated = entry.Truncated || e.Response.Truncated
+	}
+	return entry
+}
+
+func harHeaders(headers map[string][]string) []harNameValue {
+	result := []harNameValue{}
+	for name, values := range headers {
+		for _, v := range values {
+			result = append(result, harNameValue{Name: name, Value: v})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func firstHeader(headers map[string][]string, name string) string {
+	if values := http.Header(headers).Values(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
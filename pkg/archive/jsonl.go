@@ -0,0 +1,17 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+)
+
+// jsonlEncoder writes one json entry per line.
+type jsonlEncoder struct{}
+
+func (jsonlEncoder) encode(w *bufio.Writer, e Entry) error {
+	return json.NewEncoder(w).Encode(e)
+}
+
+func (jsonlEncoder) close(w *bufio.Writer) error {
+	return nil
+}
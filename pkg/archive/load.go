@@ -0,0 +1,97 @@
+package archive
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Load reads back the entries of an archive. Files ending in .har are read
+// as HAR, anything else as jsonl.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(strings.ToLower(path), "."+FormatHAR) {
+		return loadHAR(f)
+	}
+	return loadJSONL(f)
+}
+
+func loadJSONL(r io.Reader) ([]Entry, error) {
+	entries := []Entry{}
+	dec := json.NewDecoder(r)
+	for {
+		var e Entry
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+}
+
+func loadHAR(r io.Reader) ([]Entry, error) {
+	var har struct {
+		Log struct {
+			Entries []harEntry `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(har.Log.Entries))
+	for _, h := range har.Log.Entries {
+		started, err := time.Parse(time.RFC3339Nano, h.StartedDateTime)
+		if err != nil {
+			return nil, err
+		}
+
+		e := Entry{
+			Kind:          h.Kind,
+			CorrelationID: h.CorrelationID,
+			StartedAt:     started,
+			FinishedAt:    started.Add(time.Duration(h.Time * float64(time.Millisecond))),
+			Request: Message{
+				Method:    h.Request.Method,
+				URL:       h.Request.URL,
+				Headers:   fromHARHeaders(h.Request.Headers),
+				BodySize:  h.Request.BodySize,
+				Truncated: h.Truncated,
+			},
+			Error:        h.Error,
+			OrphanReason: h.OrphanReason,
+		}
+		if h.Request.PostData != nil {
+			e.Request.Body = h.Request.PostData.Text
+		}
+		if h.Response.Status != 0 || len(h.Response.Headers) > 0 {
+			e.Response = &Message{
+				Status:   h.Response.Status,
+				Headers:  fromHARHeaders(h.Response.Headers),
+				Body:     h.Response.Content.Text,
+				BodySize: h.Response.Content.Size,
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func fromHARHeaders(headers []harNameValue) map[string][]string {
+	result := http.Header{}
+	for _, h := range headers {
+		result.Add(h.Name, h.Value)
+	}
+	return result
+}
@@ -0,0 +1,308 @@
+// Package assertions checks that callbacks carry the expected payload.
+package assertions
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// Message is a decoded callback, or the request that triggered it.
+type Message struct {
+	Header http.Header
+	// Body is nil when the payload is not a json object
+	Body map[string]any
+}
+
+// Failure is a rule a callback didn't satisfy.
+type Failure struct {
+	Rule    string
+	Message string
+}
+
+type check func(value any, found bool, request *Message) error
+
+// Rule is a compiled expectation.
+type Rule struct {
+	Name string
+	// NeedsRequest is set when the original request is compared against
+	NeedsRequest bool
+
+	path   types.Locator
+	checks []check
+	schema *jsonschema.Schema
+}
+
+// Compile validates expectations and turns them into rules.
+func Compile(expectations []types.Expectation) ([]*Rule, error) {
+	rules := make([]*Rule, 0, len(expectations))
+	for _, e := range expectations {
+		rule, err := compile(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expectation %q: %w", describe(e), err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func compile(e types.Expectation) (*Rule, error) {
+	rule := &Rule{Name: e.Name, path: types.Locator{Path: e.Path}}
+	if rule.Name == "" {
+		rule.Name = describe(e)
+	}
+
+	if e.Schema != "" {
+		schema, err := jsonschema.Compile(e.Schema)
+		if err != nil {
+			return nil, err
+		}
+		rule.schema = schema
+	}
+
+	if e.Path == "" {
+		if rule.schema == nil {
+			return nil, errors.New("path is required")
+		}
+		return rule, nil
+	}
+	if rule.path.GetRootType() == types.RootUnknown {
+		return nil, errors.New("Unknown root type: " + rule.path.GetRootTypeString())
+	}
+
+	if e.Present != nil {
+		rule.checks = append(rule.checks, presentCheck(*e.Present))
+	}
+	if e.Equals != nil {
+		rule.checks = append(rule.checks, equalsCheck(e.Equals))
+	}
+	if e.Matches != "" {
+		re, err := regexp.Compile(e.Matches)
+		if err != nil {
+			return nil, err
+		}
+		rule.checks = append(rule.checks, matchesCheck(re))
+	}
+	if e.Min != nil || e.Max != nil {
+		rule.checks = append(rule.checks, rangeCheck(e.Min, e.Max))
+	}
+	if e.EqualsRequest != "" {
+		requestPath := types.Locator{Path: e.EqualsRequest}
+		if requestPath.GetRootType() == types.RootUnknown {
+			return nil, errors.New("Unknown root type: " + requestPath.GetRootTypeString())
+		}
+		rule.checks = append(rule.checks, equalsRequestCheck(requestPath))
+		rule.NeedsRequest = true
+	}
+	if len(rule.checks) == 0 && rule.schema == nil {
+		// a bare path asserts the value is there
+		rule.checks = append(rule.checks, presentCheck(true))
+	}
+	return rule, nil
+}
+
+// Evaluate runs every rule against callback. request is only called when a
+// rule compares against the original request.
+func Evaluate(rules []*Rule, callback Message, request func() (*Message, error)) []Failure {
+	var failures []Failure
+	var original *Message
+	var originalErr error
+	requested := false
+
+	for _, rule := range rules {
+		if rule.NeedsRequest && !requested {
+			original, originalErr = request()
+			requested = true
+		}
+		if err := rule.evaluate(callback, original, originalErr); err != nil {
+			failures = append(failures, Failure{Rule: rule.Name, Message: err.Error()})
+		}
+	}
+	return failures
+}
+
+func (r *Rule) evaluate(callback Message, request *Message, requestErr error) error {
+	if r.schema != nil {
+		if callback.Body == nil {
+			return errors.New("body is not a json object")
+		}
+		if err := r.schema.Validate(callback.Body); err != nil {
+			return err
+		}
+	}
+	if len(r.checks) == 0 {
+		return nil
+	}
+	if r.NeedsRequest && requestErr != nil {
+		return fmt.Errorf("original request not available: %w", requestErr)
+	}
+
+	value, found := lookup(r.path, callback)
+	for _, c := range r.checks {
+		if err := c(value, found, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lookup(locator types.Locator, m Message) (any, bool) {
+	switch locator.GetRootType() {
+	case types.RootHeader:
+		values, found := m.Header[http.CanonicalHeaderKey(locator.GetKey())]
+		if !found || len(values) == 0 {
+			return nil, false
+		}
+		return values[0], true
+	case types.RootBody:
+		if m.Body == nil {
+			return nil, false
+		}
+		value := locator.GetByLocator(&m.Body)
+		if value == nil {
+			return nil, false
+		}
+		return *value, true
+	}
+	return nil, false
+}
+
+func presentCheck(present bool) check {
+	return func(value any, found bool, _ *Message) error {
+		if found && !present {
+			return fmt.Errorf("expected to be absent, got %v", value)
+		}
+		if !found && present {
+			return errors.New("expected to be present")
+		}
+		return nil
+	}
+}
+
+func equalsCheck(expected any) check {
+	return func(value any, found bool, _ *Message) error {
+		if !found {
+			return errors.New("missing")
+		}
+		if !equal(value, expected) {
+			return fmt.Errorf("expected %v, got %v", expected, value)
+		}
+		return nil
+	}
+}
+
+func matchesCheck(re *regexp.Regexp) check {
+	return func(value any, found bool, _ *Message) error {
+		if !found {
+			return errors.New("missing")
+		}
+		if !re.MatchString(fmt.Sprint(value)) {
+			return fmt.Errorf("%v does not match %s", value, re)
+		}
+		return nil
+	}
+}
+
+func rangeCheck(min, max *float64) check {
+	return func(value any, found bool, _ *Message) error {
+		if !found {
+			return errors.New("missing")
+		}
+		n, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("expected a number, got %v", value)
+		}
+		if min != nil && n < *min {
+			return fmt.Errorf("%v is below %v", value, *min)
+		}
+		if max != nil && n > *max {
+			return fmt.Errorf("%v is above %v", value, *max)
+		}
+		return nil
+	}
+}
+
+func equalsRequestCheck(requestPath types.Locator) check {
+	return func(value any, found bool, request *Message) error {
+		expected, requestFound := lookup(requestPath, *request)
+		if !requestFound {
+			return fmt.Errorf("%s missing from request", requestPath.Path)
+		}
+		if !found {
+			return errors.New("missing")
+		}
+		if !equal(value, expected) {
+			return fmt.Errorf("expected %v from request, got %v", expected, value)
+		}
+		return nil
+	}
+}
+
+// equal compares numbers by value, whatever their type, and everything else
+// by its printed form.
+func equal(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func describe(e types.Expectation) string {
+	var parts []string
+	if e.Path != "" {
+		parts = append(parts, e.Path)
+	}
+	if e.Present != nil {
+		if *e.Present {
+			parts = append(parts, "present")
+		} else {
+			parts = append(parts, "absent")
+		}
+	}
+	if e.Equals != nil {
+		parts = append(parts, fmt.Sprintf("equals %v", e.Equals))
+	}
+	if e.Matches != "" {
+		parts = append(parts, "matches "+e.Matches)
+	}
+	if e.Min != nil {
+		parts = append(parts, fmt.Sprintf(">= %v", *e.Min))
+	}
+	if e.Max != nil {
+		parts = append(parts, fmt.Sprintf("<= %v", *e.Max))
+	}
+	if e.EqualsRequest != "" {
+		parts = append(parts, "equals request "+e.EqualsRequest)
+	}
+	if e.Schema != "" {
+		parts = append(parts, "matches schema "+e.Schema)
+	}
+	return strings.Join(parts, " ")
+}
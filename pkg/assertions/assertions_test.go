@@ -0,0 +1,94 @@
+package assertions
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestEvaluate(t *testing.T) {
+	schemaPath := filepath.Join(t.TempDir(), "callback.schema.json")
+	os.WriteFile(schemaPath, []byte(`{
+		"type": "object",
+		"required": ["status", "amount"],
+		"properties": {"amount": {"type": "number"}}
+	}`), 0644)
+
+	callback := Message{
+		Header: http.Header{"X-Event": []string{"job.done"}},
+		Body: map[string]any{
+			"status": "done",
+			"amount": float64(42),
+			"job":    map[string]any{"id": "job_123"},
+		},
+	}
+	request := &Message{
+		Body: map[string]any{"amount": float64(42), "currency": "EUR"},
+	}
+
+	cases := []struct {
+		expectation types.Expectation
+		pass        bool
+	}{
+		{types.Expectation{Path: "body.status", Equals: "done"}, true},
+		{types.Expectation{Path: "body.status", Equals: "failed"}, false},
+		{types.Expectation{Path: "body.amount", Equals: 42}, true},
+		{types.Expectation{Path: "body.job.id", Matches: "^job_[0-9]+$"}, true},
+		{types.Expectation{Path: "headers.x-event", Matches: "^payment\\."}, false},
+		{types.Expectation{Path: "body.amount", Min: ptr(1.0), Max: ptr(100.0)}, true},
+		{types.Expectation{Path: "body.amount", Max: ptr(10.0)}, false},
+		{types.Expectation{Path: "body.error", Present: ptr(false)}, true},
+		{types.Expectation{Path: "body.status", Present: ptr(false)}, false},
+		{types.Expectation{Path: "body.missing"}, false},
+		{types.Expectation{Path: "body.amount", EqualsRequest: "body.amount"}, true},
+		{types.Expectation{Path: "body.status", EqualsRequest: "body.currency"}, false},
+		{types.Expectation{Schema: schemaPath}, true},
+	}
+
+	for _, c := range cases {
+		rules, err := Compile([]types.Expectation{c.expectation})
+		if err != nil {
+			t.Fatalf("%s: Compile failed: %v", describe(c.expectation), err)
+		}
+
+		failures := Evaluate(rules, callback, func() (*Message, error) { return request, nil })
+		if passed := len(failures) == 0; passed != c.pass {
+			t.Errorf("%s: expected pass=%v, got failures %v", rules[0].Name, c.pass, failures)
+		}
+	}
+}
+
+func TestEvaluate_SchemaRejectsInvalidBody(t *testing.T) {
+	schemaPath := filepath.Join(t.TempDir(), "callback.schema.json")
+	os.WriteFile(schemaPath, []byte(`{"type": "object", "required": ["status"]}`), 0644)
+
+	rules, err := Compile([]types.Expectation{{Name: "callback schema", Schema: schemaPath}})
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	failures := Evaluate(rules, Message{Body: map[string]any{"other": 1}}, nil)
+	if len(failures) != 1 || failures[0].Rule != "callback schema" {
+		t.Errorf("Expected a single schema failure, got %v", failures)
+	}
+}
+
+func TestCompile_RejectsInvalidRules(t *testing.T) {
+	invalid := []types.Expectation{
+		{Equals: "done"},
+		{Path: "query.status", Equals: "done"},
+		{Path: "body.status", Matches: "("},
+	}
+	for _, e := range invalid {
+		if _, err := Compile([]types.Expectation{e}); err == nil {
+			t.Errorf("%s: expected Compile to fail", describe(e))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package broker talks to the message brokers some services use to signal
+// completion instead of calling a url.
+package broker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	KindNATS  = "nats"
+	KindKafka = "kafka"
+	KindRedis = "redis"
+	KindAMQP  = "amqp"
+)
+
+// Message is a single message read from or published to a broker.
+type Message struct {
+	Topic  string
+	Header http.Header
+	Body   []byte
+	// Time is when the message was read
+	Time time.Time
+}
+
+// Publisher publishes messages to a topic.
+type Publisher interface {
+	// Publish sends msg to the configured topic, msg.Topic is ignored.
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// Subscriber consumes messages from a topic.
+type Subscriber interface {
+	// Subscribe delivers every message published from now on to handle,
+	// handle may be called from several goroutines.
+	Subscribe(ctx context.Context, handle func(Message)) error
+	Close() error
+}
+
+// NewSubscriber builds a subscriber for the broker cfg points at.
+func NewSubscriber(cfg types.BrokerConfig) (Subscriber, error) {
+	if cfg.Topic == "" {
+		return nil, errors.New("Broker topic is missing")
+	}
+
+	switch cfg.Kind {
+	case KindNATS:
+		return &natsSubscriber{cfg: cfg}, nil
+	case KindKafka:
+		if len(cfg.Brokers) == 0 {
+			return nil, errors.New("Kafka brokers are missing")
+		}
+		return &kafkaSubscriber{cfg: cfg}, nil
+	case KindRedis:
+		return &redisSubscriber{cfg: cfg}, nil
+	}
+	return nil, errors.New("Unknown broker kind: " + cfg.Kind)
+}
+
+// NewPublisher connects to the broker cfg points at.
+func NewPublisher(cfg types.BrokerConfig) (Publisher, error) {
+	if cfg.Topic == "" {
+		return nil, errors.New("Broker topic is missing")
+	}
+
+	switch cfg.Kind {
+	case KindNATS:
+		return newNATSPublisher(cfg)
+	case KindKafka:
+		if len(cfg.Brokers) == 0 {
+			return nil, errors.New("Kafka brokers are missing")
+		}
+		return newKafkaPublisher(cfg)
+	case KindRedis:
+		return newRedisPublisher(cfg)
+	case KindAMQP:
+		return newAMQPPublisher(cfg)
+	}
+	return nil, errors.New("Unknown broker kind: " + cfg.Kind)
+}
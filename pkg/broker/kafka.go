@@ -0,0 +1,105 @@
+package broker
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type kafkaSubscriber struct {
+	cfg    types.BrokerConfig
+	client *kgo.Client
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *kafkaSubscriber) Subscribe(ctx context.Context, handle func(Message)) error {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(s.cfg.Brokers...),
+		kgo.ConsumeTopics(s.cfg.Topic),
+		// start from the subscription time rather than the end of the
+		// topic, the end is only resolved on the first fetch and messages
+		// published in between would be missed
+		kgo.ConsumeResetOffset(kgo.NewOffset().AfterMilli(time.Now().UnixMilli())),
+	}
+	if s.cfg.Group != "" {
+		opts = append(opts, kgo.ConsumerGroup(s.cfg.Group))
+	}
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return err
+	}
+	s.client = client
+
+	pollCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		for {
+			fetches := client.PollFetches(pollCtx)
+			if pollCtx.Err() != nil {
+				return
+			}
+			now := time.Now()
+			fetches.EachRecord(func(record *kgo.Record) {
+				header := http.Header{}
+				for _, h := range record.Headers {
+					header.Add(h.Key, string(h.Value))
+				}
+				handle(Message{
+					Topic:  record.Topic,
+					Header: header,
+					Body:   record.Value,
+					Time:   now,
+				})
+			})
+		}
+	}()
+	return nil
+}
+
+func (s *kafkaSubscriber) Close() error {
+	if s.client == nil {
+		return nil
+	}
+	s.cancel()
+	<-s.done
+	s.client.Close()
+	return nil
+}
+
+type kafkaPublisher struct {
+	cfg    types.BrokerConfig
+	client *kgo.Client
+}
+
+func newKafkaPublisher(cfg types.BrokerConfig) (Publisher, error) {
+	client, err := kgo.NewClient(kgo.SeedBrokers(cfg.Brokers...))
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaPublisher{cfg: cfg, client: client}, nil
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, msg Message) error {
+	record := &kgo.Record{Topic: p.cfg.Topic, Value: msg.Body}
+	for k, values := range msg.Header {
+		for _, v := range values {
+			record.Headers = append(record.Headers, kgo.RecordHeader{Key: k, Value: []byte(v)})
+		}
+	}
+	return p.client.ProduceSync(ctx, record).FirstErr()
+}
+
+func (p *kafkaPublisher) Close() error {
+	p.client.Close()
+	return nil
+}
@@ -0,0 +1,101 @@
+package broker
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+type natsSubscriber struct {
+	cfg  types.BrokerConfig
+	conn *nats.Conn
+}
+
+func connectNATS(cfg types.BrokerConfig) (*nats.Conn, error) {
+	url := cfg.URL
+	if url == "" {
+		url = nats.DefaultURL
+	}
+	return nats.Connect(url)
+}
+
+func (s *natsSubscriber) Subscribe(_ context.Context, handle func(Message)) error {
+	conn, err := connectNATS(s.cfg)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+
+	onMessage := func(msg *nats.Msg) {
+		handle(Message{
+			Topic:  msg.Subject,
+			Header: toHTTPHeader(msg.Header),
+			Body:   msg.Data,
+			Time:   time.Now(),
+		})
+	}
+	if s.cfg.Group != "" {
+		_, err = conn.QueueSubscribe(s.cfg.Topic, s.cfg.Group, onMessage)
+	} else {
+		_, err = conn.Subscribe(s.cfg.Topic, onMessage)
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	// the subscription only counts once the server knows about it
+	return conn.Flush()
+}
+
+func (s *natsSubscriber) Close() error {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	return nil
+}
+
+type natsPublisher struct {
+	cfg  types.BrokerConfig
+	conn *nats.Conn
+}
+
+func newNATSPublisher(cfg types.BrokerConfig) (Publisher, error) {
+	conn, err := connectNATS(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &natsPublisher{cfg: cfg, conn: conn}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, msg Message) error {
+	m := nats.NewMsg(p.cfg.Topic)
+	m.Data = msg.Body
+	for k, values := range msg.Header {
+		m.Header[k] = values
+	}
+	return p.conn.PublishMsg(m)
+}
+
+func (p *natsPublisher) Close() error {
+	// deliver what is still buffered before going away
+	err := p.conn.Drain()
+	if err != nil {
+		p.conn.Close()
+	}
+	return err
+}
+
+// toHTTPHeader canonicalizes header names so that pickers find them
+// whatever case they were published with.
+func toHTTPHeader(header map[string][]string) http.Header {
+	result := http.Header{}
+	for k, values := range header {
+		for _, v := range values {
+			result.Add(k, v)
+		}
+	}
+	return result
+}
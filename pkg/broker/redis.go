@@ -0,0 +1,140 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const DEFAULT_BODY_FIELD = "body"
+
+type redisSubscriber struct {
+	cfg    types.BrokerConfig
+	client *redis.Client
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newRedisClient(cfg types.BrokerConfig) (*redis.Client, error) {
+	url := cfg.URL
+	if url == "" {
+		url = "redis://localhost:6379"
+	}
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(opts), nil
+}
+
+func (s *redisSubscriber) Subscribe(ctx context.Context, handle func(Message)) error {
+	client, err := newRedisClient(s.cfg)
+	if err != nil {
+		return err
+	}
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return err
+	}
+	s.client = client
+
+	bodyField := redisBodyField(s.cfg)
+
+	readCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	// ids start with a millisecond timestamp, reading after the last
+	// possible id of the previous millisecond picks up anything added from
+	// now on
+	lastID := fmt.Sprintf("%d-%d", time.Now().UnixMilli()-1, uint64(math.MaxUint64))
+	go func() {
+		defer close(s.done)
+		for readCtx.Err() == nil {
+			streams, err := client.XRead(readCtx, &redis.XReadArgs{
+				Streams: []string{s.cfg.Topic, lastID},
+				Block:   time.Second,
+			}).Result()
+			if err == redis.Nil || readCtx.Err() != nil {
+				continue
+			}
+			if err != nil {
+				slog.Error("Failed to read redis stream", "stream", s.cfg.Topic, "err", err)
+				time.Sleep(time.Second)
+				continue
+			}
+
+			now := time.Now()
+			for _, stream := range streams {
+				for _, entry := range stream.Messages {
+					lastID = entry.ID
+					handle(toMessage(stream.Stream, entry, bodyField, now))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *redisSubscriber) Close() error {
+	if s.client == nil {
+		return nil
+	}
+	s.cancel()
+	<-s.done
+	return s.client.Close()
+}
+
+// toMessage reads bodyField as the payload and every other field as a
+// header.
+func toMessage(stream string, entry redis.XMessage, bodyField string, now time.Time) Message {
+	msg := Message{Topic: stream, Header: http.Header{}, Time: now}
+	for field, value := range entry.Values {
+		text := fmt.Sprint(value)
+		if field == bodyField {
+			msg.Body = []byte(text)
+		} else {
+			msg.Header.Add(field, text)
+		}
+	}
+	return msg
+}
+
+func redisBodyField(cfg types.BrokerConfig) string {
+	if cfg.BodyField == "" {
+		return DEFAULT_BODY_FIELD
+	}
+	return cfg.BodyField
+}
+
+type redisPublisher struct {
+	cfg    types.BrokerConfig
+	client *redis.Client
+}
+
+func newRedisPublisher(cfg types.BrokerConfig) (Publisher, error) {
+	client, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &redisPublisher{cfg: cfg, client: client}, nil
+}
+
+// Publish adds an entry with the body under the body field and every
+// header as a field of its own.
+func (p *redisPublisher) Publish(ctx context.Context, msg Message) error {
+	values := map[string]any{redisBodyField(p.cfg): string(msg.Body)}
+	for k := range msg.Header {
+		values[k] = msg.Header.Get(k)
+	}
+	return p.client.XAdd(ctx, &redis.XAddArgs{Stream: p.cfg.Topic, Values: values}).Err()
+}
+
+func (p *redisPublisher) Close() error {
+	return p.client.Close()
+}
@@ -0,0 +1,249 @@
+package consumer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/scheduler"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/signature"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// eventData is what body and header templates are rendered with.
+type eventData struct {
+	ID   string    // fresh for every webhook
+	Type string    // type of the event
+	Seq  int       // position of the webhook in the run, from 0
+	Time time.Time // when the webhook is sent
+}
+
+var templateFuncs = template.FuncMap{
+	"uuid": func() string { return uuid.New().String() },
+	"json": func(v any) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+}
+
+// randFuncs are the template funcs that draw from rnd, so seeded runs
+// emit the same bodies. Templates are parsed with them bound to nil and
+// rebound for every webhook.
+func randFuncs(rnd *rand.Rand) template.FuncMap {
+	return template.FuncMap{
+		"randInt": func(min, max int) int {
+			if max <= min {
+				return min
+			}
+			return min + rnd.Intn(max-min)
+		},
+		"pick": func(values ...string) string {
+			if len(values) == 0 {
+				return ""
+			}
+			return values[rnd.Intn(len(values))]
+		},
+	}
+}
+
+// render executes tmpl with its random funcs drawing from rnd.
+func render(w io.Writer, tmpl *template.Template, data eventData, rnd *rand.Rand) error {
+	bound, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	return bound.Funcs(randFuncs(rnd)).Execute(w, data)
+}
+
+type event struct {
+	name    string
+	weight  int
+	body    *template.Template
+	headers map[string]*template.Template
+}
+
+// Emitter synthesizes webhooks from templates and sends them to a webhook
+// consumer.
+type Emitter struct {
+	config  *types.InputConfig
+	events  []*event
+	weights int
+	signer  signature.Scheme
+	arrival scheduler.Arrival
+	rand    *rand.Rand
+	runner  *runner
+}
+
+func NewEmitter(config *types.InputConfig) (*Emitter, error) {
+	cfg := config.Emit
+	if cfg.Target == "" {
+		return nil, errors.New("Emit target is missing")
+	}
+	if len(cfg.Events) == 0 {
+		return nil, errors.New("Emit needs at least one event")
+	}
+	if config.Run.Iterations <= 0 {
+		return nil, errors.New("Emit needs a positive number of iterations")
+	}
+	if err := scheduler.ValidatePolicy(config.Run.InFlightPolicy); err != nil {
+		return nil, err
+	}
+	if err := reporter.ValidateThresholds(config.Report.Thresholds); err != nil {
+		return nil, err
+	}
+
+	e := &Emitter{config: config}
+	for i, eventCfg := range cfg.Events {
+		ev, err := parseEvent(i, eventCfg)
+		if err != nil {
+			return nil, err
+		}
+		e.events = append(e.events, ev)
+		e.weights += ev.weight
+	}
+
+	meanGap := scheduler.MeanGap(config.Run.Iterations, time.Duration(config.Run.DurationSeconds)*time.Second)
+	arrival, err := scheduler.NewArrival(config.Run.Arrival, meanGap)
+	if err != nil {
+		return nil, err
+	}
+	e.arrival = arrival
+
+	seed := config.Run.Arrival.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	e.rand = rand.New(rand.NewSource(seed))
+
+	signer, err := signature.New(cfg.Sign)
+	if err != nil {
+		return nil, err
+	}
+	e.signer = signer
+	e.runner = newRunner(config.Run, cfg.TimeoutSeconds)
+	return e, nil
+}
+
+func parseEvent(i int, cfg types.EventConfig) (*event, error) {
+	name := cfg.Type
+	if name == "" {
+		name = "event " + strconv.Itoa(i+1)
+	}
+	if cfg.Weight < 0 {
+		return nil, errors.New("Event weight must be positive: " + name)
+	}
+	weight := cfg.Weight
+	if weight == 0 {
+		weight = 1
+	}
+
+	body, err := template.New(name).Funcs(templateFuncs).Funcs(randFuncs(nil)).Parse(cfg.Body)
+	if err != nil {
+		return nil, errors.New("Invalid body template for " + name + ": " + err.Error())
+	}
+
+	headers := map[string]*template.Template{}
+	for k, v := range cfg.Headers {
+		header, err := template.New(k).Funcs(templateFuncs).Funcs(randFuncs(nil)).Parse(v)
+		if err != nil {
+			return nil, errors.New("Invalid header template for " + name + ": " + err.Error())
+		}
+		headers[k] = header
+	}
+
+	return &event{name: cfg.Type, weight: weight, body: body, headers: headers}, nil
+}
+
+// Run sends every webhook and blocks until the consumer answered all of
+// them.
+func (e *Emitter) Run() reporter.Metrics {
+	jobs := make([]job, e.config.Run.Iterations)
+	var offset time.Duration
+	for i := range jobs {
+		ev := e.pickEvent()
+		seq := i
+		// webhooks are rendered concurrently, each draws from its own
+		// source seeded in order
+		seed := e.rand.Int63()
+		jobs[i] = job{
+			key:    strconv.Itoa(i),
+			offset: offset,
+			build: func(now time.Time) (*http.Request, error) {
+				return e.buildRequest(ev, seq, now, rand.New(rand.NewSource(seed)))
+			},
+		}
+		offset += e.arrival.Next()
+	}
+
+	elapsed := e.runner.send(jobs)
+	return e.runner.metrics(e.config, elapsed)
+}
+
+func (e *Emitter) pickEvent() *event {
+	n := e.rand.Intn(e.weights)
+	for _, ev := range e.events {
+		if n < ev.weight {
+			return ev
+		}
+		n -= ev.weight
+	}
+	return e.events[len(e.events)-1]
+}
+
+func (e *Emitter) buildRequest(ev *event, seq int, now time.Time, rnd *rand.Rand) (*http.Request, error) {
+	data := eventData{
+		ID:   uuid.New().String(),
+		Type: ev.name,
+		Seq:  seq,
+		Time: now,
+	}
+
+	var body bytes.Buffer
+	if err := render(&body, ev.body, data, rnd); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.config.Emit.Target, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.config.Emit.Headers {
+		req.Header.Set(k, v)
+	}
+	// in a fixed order, so headers draw the same values on every run
+	names := make([]string, 0, len(ev.headers))
+	for k := range ev.headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		var value strings.Builder
+		if err := render(&value, ev.headers[k], data, rnd); err != nil {
+			return nil, err
+		}
+		req.Header.Set(k, value.String())
+	}
+
+	if e.signer != nil {
+		if err := e.signer.Sign(req.Header, body.Bytes(), now); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// Records are the webhooks sent by Run and how the consumer answered them.
+func (e *Emitter) Records() reporter.Records {
+	return e.runner.reqTracker
+}
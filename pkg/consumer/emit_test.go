@@ -0,0 +1,139 @@
+package consumer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/signature"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func TestEmitMixesEvents(t *testing.T) {
+	signCfg := types.SignatureConfig{Scheme: signature.SchemeStandardWebhooks, Secret: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"}
+	verifier, err := signature.New(signCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	seen := map[string]int{}
+	ids := map[string]bool{}
+	consumer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := verifier.Verify(r.Header, body, time.Now()); err != nil {
+			t.Errorf("webhook not signed: %v", err)
+		}
+
+		var payload struct {
+			ID     string
+			Type   string
+			Amount int
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("body is not json: %s", body)
+		}
+		if payload.Type != r.Header.Get("X-Event-Type") {
+			t.Errorf("header type %q does not match body type %q", r.Header.Get("X-Event-Type"), payload.Type)
+		}
+		if payload.Amount < 100 || payload.Amount >= 200 {
+			t.Errorf("amount %d out of range", payload.Amount)
+		}
+
+		lock.Lock()
+		seen[payload.Type]++
+		ids[payload.ID] = true
+		lock.Unlock()
+
+		if payload.Type == "invoice.failed" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer consumer.Close()
+
+	body := `{"id": {{json .ID}}, "type": {{json .Type}}, "amount": {{randInt 100 200}}}`
+	config := &types.InputConfig{
+		Run: types.RunConfig{Iterations: 200, DurationSeconds: 0, Arrival: types.ArrivalConfig{Seed: 7}},
+		Emit: types.EmitConfig{
+			Target: consumer.URL,
+			Sign:   signCfg,
+			Events: []types.EventConfig{
+				{Type: "invoice.paid", Weight: 3, Body: body, Headers: map[string]string{"X-Event-Type": "{{.Type}}"}},
+				{Type: "invoice.failed", Weight: 1, Body: body, Headers: map[string]string{"X-Event-Type": "{{.Type}}"}},
+			},
+		},
+	}
+
+	emitter, err := NewEmitter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := emitter.Run()
+
+	if metrics.TotalRequests != 200 || len(ids) != 200 {
+		t.Errorf("sent %d webhooks with %d distinct ids, want 200", metrics.TotalRequests, len(ids))
+	}
+	if metrics.FailedRequests != seen["invoice.failed"] || metrics.StatusCodes[http.StatusBadRequest] != seen["invoice.failed"] {
+		t.Errorf("FailedRequests = %d, want %d", metrics.FailedRequests, seen["invoice.failed"])
+	}
+	// a 3:1 mix
+	if paid := seen["invoice.paid"]; paid < 120 || paid > 180 {
+		t.Errorf("got %d invoice.paid out of 200, want about 150", paid)
+	}
+}
+
+func TestEmitSeedRepeatsBodies(t *testing.T) {
+	emit := func() map[string]string {
+		var lock sync.Mutex
+		sent := map[string]string{}
+		consumer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			lock.Lock()
+			sent[r.Header.Get("X-Seq")] = string(body) + " " + r.Header.Get("X-Region")
+			lock.Unlock()
+		}))
+		defer consumer.Close()
+
+		config := &types.InputConfig{
+			Run: types.RunConfig{Iterations: 50, MaxInFlight: 8, Arrival: types.ArrivalConfig{Seed: 7}},
+			Emit: types.EmitConfig{
+				Target: consumer.URL,
+				Events: []types.EventConfig{{
+					Type:    "invoice.paid",
+					Body:    `{"amount": {{randInt 0 1000000}}, "reason": {{json (pick "a" "b" "c" "d")}}}`,
+					Headers: map[string]string{"X-Seq": "{{.Seq}}", "X-Region": `{{pick "eu" "us" "ap"}}`},
+				}},
+			},
+		}
+		emitter, err := NewEmitter(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		emitter.Run()
+		return sent
+	}
+
+	first, second := emit(), emit()
+	if len(first) != 50 {
+		t.Fatalf("Expected 50 webhooks, got %d", len(first))
+	}
+	for seq, body := range first {
+		if second[seq] != body {
+			t.Errorf("Webhook %s: expected the same seed to emit %q, got %q", seq, body, second[seq])
+		}
+	}
+}
+
+func TestEmitRejectsBadTemplates(t *testing.T) {
+	config := &types.InputConfig{
+		Run:  types.RunConfig{Iterations: 1},
+		Emit: types.EmitConfig{Target: "http://localhost:1", Events: []types.EventConfig{{Body: "{{.Nope"}}},
+	}
+	if _, err := NewEmitter(config); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,157 @@
+package consumer
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/archive"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/scheduler"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/signature"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// skippedHeaders are not copied from the archive, the client sets them
+// again for the new connection.
+var skippedHeaders = []string{"Content-Length", "Host", "Connection", "Accept-Encoding", "Transfer-Encoding"}
+
+// Replayer re-sends the callbacks of an archive to a webhook consumer.
+type Replayer struct {
+	config    *types.InputConfig
+	callbacks []archive.Entry
+	signer    signature.Scheme
+	runner    *runner
+}
+
+func NewReplayer(config *types.InputConfig) (*Replayer, error) {
+	cfg := config.Replay
+	if cfg.Archive == "" {
+		return nil, errors.New("Replay archive is missing")
+	}
+	if cfg.Target == "" {
+		return nil, errors.New("Replay target is missing")
+	}
+	if cfg.Rate < 0 || cfg.Speed < 0 {
+		return nil, errors.New("Replay rate and speed must be positive")
+	}
+	if err := scheduler.ValidatePolicy(config.Run.InFlightPolicy); err != nil {
+		return nil, err
+	}
+	if err := reporter.ValidateThresholds(config.Report.Thresholds); err != nil {
+		return nil, err
+	}
+
+	entries, err := archive.Load(cfg.Archive)
+	if err != nil {
+		return nil, err
+	}
+	callbacks := []archive.Entry{}
+	for _, e := range entries {
+		if e.Kind == archive.KindCallback {
+			callbacks = append(callbacks, e)
+		}
+	}
+	if len(callbacks) == 0 {
+		return nil, errors.New("No callbacks found in archive: " + cfg.Archive)
+	}
+	sort.SliceStable(callbacks, func(i, j int) bool {
+		return callbacks[i].StartedAt.Before(callbacks[j].StartedAt)
+	})
+
+	signer, err := signature.New(cfg.Sign)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Replayer{
+		config:    config,
+		callbacks: callbacks,
+		signer:    signer,
+		runner:    newRunner(config.Run, cfg.TimeoutSeconds),
+	}, nil
+}
+
+// Run replays every callback and blocks until the consumer answered all of
+// them.
+func (rp *Replayer) Run() reporter.Metrics {
+	truncated := 0
+	jobs := make([]job, len(rp.callbacks))
+	for i, callback := range rp.callbacks {
+		if callback.Request.Truncated {
+			truncated++
+		}
+		callback := callback
+		jobs[i] = job{
+			key:    strconv.Itoa(i),
+			offset: rp.offset(i),
+			build: func(now time.Time) (*http.Request, error) {
+				return rp.buildRequest(callback, now)
+			},
+		}
+	}
+	if truncated > 0 {
+		slog.Warn("Replaying callbacks with truncated bodies", "count", truncated)
+	}
+
+	elapsed := rp.runner.send(jobs)
+	return rp.runner.metrics(rp.config, elapsed)
+}
+
+// offset is when callback i is sent, relative to the first one.
+func (rp *Replayer) offset(i int) time.Duration {
+	cfg := rp.config.Replay
+	if cfg.Rate > 0 {
+		return time.Duration(float64(i) / cfg.Rate * float64(time.Second))
+	}
+
+	original := rp.callbacks[i].StartedAt.Sub(rp.callbacks[0].StartedAt)
+	if cfg.Speed > 0 {
+		return time.Duration(float64(original) / cfg.Speed)
+	}
+	return original
+}
+
+func (rp *Replayer) buildRequest(callback archive.Entry, now time.Time) (*http.Request, error) {
+	body := []byte(callback.Request.Body)
+	method := callback.Request.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequest(method, rp.config.Replay.Target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range callback.Request.Headers {
+		for _, value := range values {
+			// redacted values would only confuse the consumer
+			if value != archive.Redacted {
+				req.Header.Add(name, value)
+			}
+		}
+	}
+	for _, name := range skippedHeaders {
+		req.Header.Del(name)
+	}
+	for k, v := range rp.config.Replay.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if rp.signer != nil {
+		if err := rp.signer.Sign(req.Header, body, now); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// Records are the webhooks sent by Run and how the consumer answered them.
+func (rp *Replayer) Records() reporter.Records {
+	return rp.runner.reqTracker
+}
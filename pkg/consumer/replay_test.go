@@ -0,0 +1,110 @@
+package consumer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/archive"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/signature"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func writeArchive(t *testing.T, bodies []string, gap time.Duration) string {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	a, err := archive.New(types.ArchiveConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	a.Record(archive.Entry{Kind: archive.KindTrigger, StartedAt: start})
+	for i, body := range bodies {
+		header := http.Header{}
+		header.Set("Authorization", "Bearer token")
+		header.Set("X-Signature", "stale")
+		header.Set("X-Event", "job.finished")
+		request := a.NewMessage(header, []byte(body))
+		request.Method = http.MethodPost
+		a.Record(archive.Entry{
+			Kind:      archive.KindCallback,
+			StartedAt: start.Add(time.Duration(i) * gap),
+			Request:   request,
+		})
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReplayResignsAndReports(t *testing.T) {
+	signCfg := types.SignatureConfig{Scheme: signature.SchemeHMAC, Secret: "shh"}
+	verifier, _ := signature.New(signCfg)
+
+	var lock sync.Mutex
+	received := []time.Time{}
+	consumer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		received = append(received, time.Now())
+		lock.Unlock()
+
+		if r.Header.Get("Authorization") != "" {
+			t.Error("redacted header was replayed")
+		}
+		if r.Header.Get("X-Event") != "job.finished" {
+			t.Error("archived header was not replayed")
+		}
+		if err := verifier.Verify(r.Header, body, time.Now()); err != nil {
+			t.Errorf("replayed callback not re-signed: %v", err)
+		}
+		if string(body) == `{"fail":true}` {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer consumer.Close()
+
+	bodies := []string{`{"id":1}`, `{"fail":true}`, `{"id":3}`}
+	config := &types.InputConfig{Replay: types.ReplayConfig{
+		Archive: writeArchive(t, bodies, 400*time.Millisecond),
+		Target:  consumer.URL,
+		Speed:   4,
+		Sign:    signCfg,
+	}}
+
+	replayer, err := NewReplayer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := replayer.Run()
+
+	if metrics.TotalRequests != len(bodies) {
+		t.Errorf("TotalRequests = %d, want %d", metrics.TotalRequests, len(bodies))
+	}
+	if metrics.FailedRequests != 1 {
+		t.Errorf("FailedRequests = %d, want 1", metrics.FailedRequests)
+	}
+	if metrics.StatusCodes[http.StatusOK] != 2 || metrics.StatusCodes[http.StatusInternalServerError] != 1 {
+		t.Errorf("StatusCodes = %v, want two 200s and one 500", metrics.StatusCodes)
+	}
+
+	// 400ms gaps replayed 4 times faster
+	if spread := received[len(received)-1].Sub(received[0]); spread < 150*time.Millisecond || spread > 400*time.Millisecond {
+		t.Errorf("callbacks spread over %s, want about 200ms", spread)
+	}
+}
+
+func TestReplayNeedsCallbacks(t *testing.T) {
+	config := &types.InputConfig{Replay: types.ReplayConfig{
+		Archive: writeArchive(t, nil, 0),
+		Target:  "http://localhost:1",
+	}}
+	if _, err := NewReplayer(config); err == nil {
+		t.Error("expected an error for an archive without callbacks")
+	}
+}
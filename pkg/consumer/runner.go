@@ -0,0 +1,130 @@
+// Package consumer load tests services that consume webhooks. Instead of
+// waiting for callbacks it plays the provider and sends them.
+package consumer
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/scheduler"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+var DEFAULT_REQUEST_TIMEOUT = 30 * time.Second
+
+// job is a single webhook sent offset after the start of the run. The
+// request is built right before sending so that signatures are fresh.
+type job struct {
+	key    string
+	offset time.Duration
+	build  func(now time.Time) (*http.Request, error)
+}
+
+// runner sends jobs on schedule and records how the consumer answered.
+type runner struct {
+	client     *http.Client
+	run        types.RunConfig
+	reqTracker *tracker.Tracker
+}
+
+func newRunner(run types.RunConfig, timeoutSeconds int) *runner {
+	timeout := DEFAULT_REQUEST_TIMEOUT
+	if timeoutSeconds > 0 {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+	return &runner{
+		client:     &http.Client{Timeout: timeout},
+		run:        run,
+		reqTracker: tracker.NewRequestTracker(),
+	}
+}
+
+// send fires every job and returns once all of them were answered, along
+// with how long that took.
+func (r *runner) send(jobs []job) time.Duration {
+	pool := scheduler.NewPool(r.run.MaxInFlight, r.run.InFlightPolicy)
+
+	start := time.Now()
+	skipped := 0
+	for _, j := range jobs {
+		j := j
+		scheduledTime := start.Add(j.offset)
+		if wait := time.Until(scheduledTime); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		accepted := pool.Submit(func() {
+			r.reqTracker.Set(j.key, r.do(j, scheduledTime))
+		})
+		if !accepted {
+			slog.Debug("Too many requests in flight, skipping", "key", j.key)
+			r.reqTracker.Set(j.key, tracker.RequestTrackerPair{
+				ScheduledTime: scheduledTime,
+				Skipped:       true,
+			})
+			skipped++
+		}
+	}
+	slog.Info("Webhooks sent...", "skipped", skipped)
+
+	pool.Close()
+	pool.Wait()
+	return time.Since(start)
+}
+
+func (r *runner) do(j job, scheduledTime time.Time) tracker.RequestTrackerPair {
+	record := tracker.RequestTrackerPair{
+		ScheduledTime: scheduledTime,
+		StartTime:     time.Now(),
+	}
+
+	req, err := j.build(record.StartTime)
+	if err != nil {
+		return failed(record, err.Error())
+	}
+	if req.ContentLength > 0 {
+		record.RequestBytes = int(req.ContentLength)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return failed(record, err.Error())
+	}
+	read, _ := io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	// the consumer's answer is all there is, so it doubles as the callback
+	record.SendEndTime = time.Now()
+	record.EndTime = record.SendEndTime
+	record.StatusCode = res.StatusCode
+	record.ResponseBytes = int(read)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return failed(record, fmt.Sprintf("consumer responded with %s", res.Status))
+	}
+	slog.Debug("Webhook sent", "key", j.key, "status", res.StatusCode)
+	return record
+}
+
+// failed marks record as a send that went wrong.
+func failed(record tracker.RequestTrackerPair, reason string) tracker.RequestTrackerPair {
+	if record.EndTime.IsZero() {
+		record.EndTime = time.Now()
+	}
+	record.SendFailed = true
+	record.Error = reason
+	return record
+}
+
+// metrics reports on every record kept by the runner, bucketed and checked
+// against thresholds as config.report asks.
+func (r *runner) metrics(config *types.InputConfig, elapsed time.Duration) reporter.Metrics {
+	bucketInterval := time.Duration(config.Report.BucketSeconds) * time.Second
+	metrics, _ := reporter.CalculateStoreMetrics(r.reqTracker, elapsed, bucketInterval)
+	metrics.Thresholds = reporter.EvaluateThresholds(metrics, config.Report.Thresholds)
+	return metrics
+}
@@ -0,0 +1,201 @@
+package distributed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/webhook_tester"
+)
+
+// startDelay gives every worker time to receive its assignment before the
+// run starts.
+var startDelay = time.Second
+
+// Controller waits for a fixed number of workers, hands each of them an
+// assignment and collects their records once they are done firing.
+type Controller struct {
+	wt          *webhook_tester.DefaultWebhookTester
+	assignments []Assignment
+
+	lock       sync.Mutex
+	registered int
+	reported   map[string]bool
+	ready      chan struct{}
+	done       chan struct{}
+}
+
+// NewController splits the run described by config between workers. The
+// receiver of wt must already be started, its url is what workers inject.
+func NewController(config *types.InputConfig, wt *webhook_tester.DefaultWebhookTester, workers int) (*Controller, error) {
+	if workers <= 0 {
+		return nil, errors.New("At least one worker is required")
+	}
+	if workers > config.Run.Iterations {
+		return nil, fmt.Errorf("Cannot split %d iterations between %d workers", config.Run.Iterations, workers)
+	}
+
+	receiverURL := wt.ReceiverURL()
+
+	ids := make([]string, config.Run.Iterations)
+	for i := range ids {
+		ids[i] = uuid.New().String()
+	}
+	wt.Expect(ids)
+
+	assignments := make([]Assignment, workers)
+	from := 0
+	for i := range assignments {
+		// spread the remainder over the first few workers
+		share := config.Run.Iterations / workers
+		if i < config.Run.Iterations%workers {
+			share++
+		}
+
+		workerID := fmt.Sprintf("worker-%d", i+1)
+		workerConfig := *config
+		workerConfig.Outputs = nil
+		// workers ship their records back, the controller keeps them
+		workerConfig.Tracker = types.TrackerConfig{}
+		if workerConfig.Archive.Path != "" {
+			// workers sharing the controller's host must not overwrite
+			// its archive, nor each other's
+			workerConfig.Archive.Path = workerArchivePath(config.Archive.Path, workerID)
+		}
+		workerConfig.Run.Iterations = share
+		if workerConfig.Run.Arrival.Seed != 0 {
+			// identical seeds would make workers burst in lockstep
+			workerConfig.Run.Arrival.Seed += int64(i)
+		}
+
+		assignments[i] = Assignment{
+			WorkerID:       workerID,
+			Config:         workerConfig,
+			ReceiverURL:    receiverURL,
+			CorrelationIDs: ids[from : from+share],
+		}
+		from += share
+	}
+
+	return &Controller{
+		wt:          wt,
+		assignments: assignments,
+		reported:    make(map[string]bool),
+		ready:       make(chan struct{}),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// workerArchivePath names the archive of a worker after the controller's,
+// eg. out/exchanges.jsonl becomes out/exchanges.worker-1.jsonl.
+func workerArchivePath(path, workerID string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + workerID + ext
+}
+
+// Handler serves the endpoints workers talk to.
+func (c *Controller) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(RegisterPath, c.handleRegister)
+	mux.HandleFunc(RecordsPath, c.handleRecords)
+	mux.HandleFunc(ClockPath, c.handleClock)
+	return mux
+}
+
+func (c *Controller) handleClock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ClockReading{Time: time.Now()})
+}
+
+func (c *Controller) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var registration Registration
+	json.NewDecoder(r.Body).Decode(&registration)
+
+	c.lock.Lock()
+	slot := c.registered
+	if slot >= len(c.assignments) {
+		c.lock.Unlock()
+		http.Error(w, "all worker slots are taken", http.StatusConflict)
+		return
+	}
+	c.registered++
+	if c.registered == len(c.assignments) {
+		startAt := time.Now().Add(startDelay)
+		for i := range c.assignments {
+			c.assignments[i].StartAt = startAt
+		}
+		close(c.ready)
+	}
+	c.lock.Unlock()
+
+	slog.Info("Worker registered", "name", registration.Name, "slot", slot+1, "of", len(c.assignments))
+
+	// hold the request until every worker has joined
+	select {
+	case <-c.ready:
+	case <-r.Context().Done():
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.assignments[slot])
+}
+
+func (c *Controller) handleRecords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var batch RecordBatch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.reported[batch.WorkerID] {
+		http.Error(w, "records already received", http.StatusConflict)
+		return
+	}
+	c.reported[batch.WorkerID] = true
+
+	slog.Info("Received worker records", "worker", batch.WorkerID, "records", len(batch.Records))
+	c.wt.MergeRecords(batch.Records)
+
+	if len(c.reported) == len(c.assignments) {
+		close(c.done)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Ready is closed once every worker has registered.
+func (c *Controller) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// WaitForWorkers blocks until every worker has shipped its records back.
+func (c *Controller) WaitForWorkers(timeout time.Duration) error {
+	select {
+	case <-c.done:
+		return nil
+	case <-time.After(timeout):
+		return types.TimedOutWaitingForWorkersErr
+	}
+}
@@ -0,0 +1,45 @@
+// Package distributed splits a load test between one controller and several
+// workers. The controller runs the receiver and hands every worker a slice
+// of the load plan, workers fire their share of requests and ship their
+// tracker records back so the controller can produce a single report.
+package distributed
+
+import (
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	RegisterPath = "/register"
+	RecordsPath  = "/records"
+	ClockPath    = "/clock"
+)
+
+// Assignment is the slice of the load plan given to a single worker.
+type Assignment struct {
+	WorkerID       string
+	Config         types.InputConfig
+	ReceiverURL    string
+	CorrelationIDs []string
+	// StartAt lines up workers so that their combined rate matches the plan
+	StartAt time.Time
+}
+
+// Registration is sent by a worker when it joins the controller.
+type Registration struct {
+	Name string
+}
+
+// ClockReading is the controller's time, workers compare it with their own
+// to correct for clock skew.
+type ClockReading struct {
+	Time time.Time
+}
+
+// RecordBatch carries the tracker records of a worker back to the controller.
+type RecordBatch struct {
+	WorkerID string
+	Records  map[string]tracker.RequestTrackerPair
+}
@@ -0,0 +1,228 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/archive"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/webhook_tester"
+)
+
+// newTarget starts an api that immediately calls back the reply path with
+// the body it received.
+func newTarget(t *testing.T) *httptest.Server {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		replyTo := r.Header.Get("webhook-reply-to")
+		go http.Post(replyTo, "application/json", bytes.NewReader(body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(target.Close)
+	return target
+}
+
+func newTestConfig(targetURL string) *types.InputConfig {
+	var config types.InputConfig
+	config.Receiver.Listen = "127.0.0.1:0"
+	config.Test.URL = targetURL
+	config.Test.Body = `{"message": "ok"}`
+	config.Test.Timeout = 5
+	config.Test.Injectors.ReplyPathInjector.Path = "headers.webhook-reply-to"
+	config.Test.Injectors.CorrelationIDInjector.Path = "body.uniqueId"
+	config.Test.Pickers.CorrelationPicker.Path = "body.uniqueId"
+	config.Run.Iterations = 21
+	config.Run.DurationSeconds = 1
+	return &config
+}
+
+// workerProcessEnv makes the test binary run as a worker of the controller
+// it names, see TestMain.
+const workerProcessEnv = "WLT_TEST_WORKER_CONTROLLER"
+
+// TestMain lets the test binary stand in for the worker command, so workers
+// can run in processes of their own.
+func TestMain(m *testing.M) {
+	if controllerURL := os.Getenv(workerProcessEnv); controllerURL != "" {
+		if err := RunWorker(context.Background(), controllerURL, "process"); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// startController starts the receiver and a controller waiting for workers,
+// it returns the controller's url.
+func startController(t *testing.T, config *types.InputConfig, workers int) (*webhook_tester.DefaultWebhookTester, *Controller, string) {
+	startDelay = 10 * time.Millisecond
+	wt := webhook_tester.NewDefaultWebhookTester(config)
+	if err := wt.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	stopReceiver, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	t.Cleanup(stopReceiver)
+
+	controller, err := NewController(config, wt, workers)
+	if err != nil {
+		t.Fatalf("NewController failed: %v", err)
+	}
+	server := httptest.NewServer(controller.Handler())
+	t.Cleanup(server.Close)
+	return wt, controller, server.URL
+}
+
+// checkMergedRecords waits for the run to end and checks every request
+// made it into the controller's records.
+func checkMergedRecords(t *testing.T, wt *webhook_tester.DefaultWebhookTester, controller *Controller, config *types.InputConfig) {
+	if err := controller.WaitForWorkers(time.Second); err != nil {
+		t.Fatalf("WaitForWorkers failed: %v", err)
+	}
+	if err := wt.WaitForResults(); err != nil {
+		t.Fatalf("WaitForResults failed: %v", err)
+	}
+
+	records := wt.Records()
+	if len(records) != config.Run.Iterations {
+		t.Fatalf("Expected %d records, got %d", config.Run.Iterations, len(records))
+	}
+	for id, record := range records {
+		if record.StartTime.IsZero() || record.EndTime.IsZero() || record.CallbackLatency() <= 0 {
+			t.Errorf("Record %s is incomplete: %+v", id, record)
+		}
+	}
+}
+
+func TestController_MergesRecordsFromWorkers(t *testing.T) {
+	config := newTestConfig(newTarget(t).URL)
+	wt, controller, controllerURL := startController(t, config, 3)
+
+	workerErrs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			workerErrs <- RunWorker(context.Background(), controllerURL, "test")
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-workerErrs; err != nil {
+			t.Fatalf("Worker failed: %v", err)
+		}
+	}
+	checkMergedRecords(t, wt, controller, config)
+}
+
+func TestController_WorkersKeepArchivesApart(t *testing.T) {
+	dir := t.TempDir()
+	config := newTestConfig(newTarget(t).URL)
+	config.Archive.Path = filepath.Join(dir, "exchanges.jsonl")
+	wt, controller, controllerURL := startController(t, config, 2)
+
+	workerErrs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			workerErrs <- RunWorker(context.Background(), controllerURL, "test")
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-workerErrs; err != nil {
+			t.Fatalf("Worker failed: %v", err)
+		}
+	}
+	checkMergedRecords(t, wt, controller, config)
+	if err := wt.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[string]int{}
+	for _, name := range []string{"exchanges.jsonl", "exchanges.worker-1.jsonl", "exchanges.worker-2.jsonl"} {
+		entries, err := archive.Load(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", name, err)
+		}
+		for _, entry := range entries {
+			kinds[name+" "+entry.Kind]++
+		}
+	}
+	triggers := kinds["exchanges.worker-1.jsonl trigger"] + kinds["exchanges.worker-2.jsonl trigger"]
+	if kinds["exchanges.jsonl callback"] != config.Run.Iterations || kinds["exchanges.jsonl trigger"] != 0 || triggers != config.Run.Iterations {
+		t.Errorf("Expected callbacks in the controller's archive and triggers in the workers', got %v", kinds)
+	}
+}
+
+func TestController_WorkerProcesses(t *testing.T) {
+	config := newTestConfig(newTarget(t).URL)
+	wt, controller, controllerURL := startController(t, config, 2)
+
+	workers := make([]*exec.Cmd, 2)
+	outputs := make([]*bytes.Buffer, 2)
+	for i := range workers {
+		outputs[i] = &bytes.Buffer{}
+		workers[i] = exec.Command(os.Args[0], "-test.run=^$")
+		workers[i].Env = append(os.Environ(), workerProcessEnv+"="+controllerURL)
+		workers[i].Stdout, workers[i].Stderr = outputs[i], outputs[i]
+		if err := workers[i].Start(); err != nil {
+			t.Fatalf("Failed to start worker: %v", err)
+		}
+	}
+	for i, worker := range workers {
+		if err := worker.Wait(); err != nil {
+			t.Fatalf("Worker failed: %v\n%s", err, outputs[i])
+		}
+	}
+	checkMergedRecords(t, wt, controller, config)
+}
+
+func TestController_RejectsExtraWorkers(t *testing.T) {
+	controller := &Controller{
+		assignments: make([]Assignment, 1),
+		registered:  1,
+		reported:    map[string]bool{},
+		ready:       make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	payload, _ := json.Marshal(Registration{Name: "late"})
+	rec := httptest.NewRecorder()
+	controller.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RegisterPath, bytes.NewReader(payload)))
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected %d, got %d", http.StatusConflict, rec.Code)
+	}
+}
+
+func TestMeasureClockOffset(t *testing.T) {
+	// a controller an hour ahead
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ClockReading{Time: time.Now().Add(time.Hour)})
+	}))
+	defer controller.Close()
+
+	offset, err := measureClockOffset(context.Background(), controller.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skew := offset - time.Hour; skew < -50*time.Millisecond || skew > 50*time.Millisecond {
+		t.Errorf("Expected an offset of about an hour, got %s", offset)
+	}
+
+	start := time.Now()
+	records := shiftRecords(map[string]tracker.RequestTrackerPair{"a": {StartTime: start}}, offset)
+	if record := records["a"]; !record.StartTime.Equal(start.Add(offset)) || !record.SendEndTime.IsZero() {
+		t.Errorf("Expected set times to move to the controller's clock, got %+v", record)
+	}
+}
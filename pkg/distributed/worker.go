@@ -0,0 +1,154 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/webhook_tester"
+)
+
+// RunWorker joins the controller at controllerURL, fires the requests it is
+// assigned and ships the resulting records back.
+func RunWorker(ctx context.Context, controllerURL string, name string) error {
+	controllerURL = strings.TrimSuffix(controllerURL, "/")
+
+	// latencies mix times taken here with callback times taken on the
+	// controller, so every time is shipped on the controller's clock
+	offset, err := measureClockOffset(ctx, controllerURL)
+	if err != nil {
+		return fmt.Errorf("failed to read the controller's clock: %w", err)
+	}
+	slog.Info("Measured clock offset to controller", "offset", offset)
+
+	slog.Info("Registering with controller", "url", controllerURL)
+	var assignment Assignment
+	if err := postJSON(ctx, controllerURL+RegisterPath, Registration{Name: name}, &assignment); err != nil {
+		return fmt.Errorf("failed to register with controller: %w", err)
+	}
+	slog.Info("Received assignment",
+		"worker", assignment.WorkerID,
+		"iterations", assignment.Config.Run.Iterations,
+		"startAt", assignment.StartAt,
+	)
+
+	wt := webhook_tester.NewDefaultWebhookTester(&assignment.Config)
+	if err := wt.LoadConfig(); err != nil {
+		return err
+	}
+	wt.UseReceiver(assignment.ReceiverURL)
+	wt.SetCorrelationIDs(assignment.CorrelationIDs)
+
+	select {
+	case <-time.After(time.Until(assignment.StartAt.Add(-offset))):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if err := wt.FireRequests(); err != nil {
+		return err
+	}
+	wt.WaitForRequests()
+	if err := wt.Close(); err != nil {
+		slog.Error("Failed to close trigger and archive", "err", err)
+	}
+
+	batch := RecordBatch{
+		WorkerID: assignment.WorkerID,
+		Records:  shiftRecords(wt.Records(), offset),
+	}
+	if err := postJSON(ctx, controllerURL+RecordsPath, batch, nil); err != nil {
+		return fmt.Errorf("failed to ship records: %w", err)
+	}
+	return nil
+}
+
+// clockSamples is how many times the controller's clock is read, the
+// reading with the shortest round trip is the most accurate.
+const clockSamples = 5
+
+// measureClockOffset estimates how far the controller's clock is ahead of
+// the local one. The controller is assumed to read its clock half way
+// through the round trip, so the estimate is off by at most half of it.
+func measureClockOffset(ctx context.Context, controllerURL string) (time.Duration, error) {
+	var offset time.Duration
+	bestRoundTrip := time.Duration(-1)
+	for i := 0; i < clockSamples; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, controllerURL+ClockPath, nil)
+		if err != nil {
+			return 0, err
+		}
+		sent := time.Now()
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		var reading ClockReading
+		err = json.NewDecoder(res.Body).Decode(&reading)
+		res.Body.Close()
+		received := time.Now()
+		if res.StatusCode >= 300 {
+			return 0, fmt.Errorf("controller responded with %s", res.Status)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		roundTrip := received.Sub(sent)
+		if bestRoundTrip < 0 || roundTrip < bestRoundTrip {
+			bestRoundTrip = roundTrip
+			offset = reading.Time.Sub(sent.Add(roundTrip / 2))
+		}
+	}
+	return offset, nil
+}
+
+// shiftRecords moves the times of records by offset, from the local clock to
+// the controller's.
+func shiftRecords(records map[string]tracker.RequestTrackerPair, offset time.Duration) map[string]tracker.RequestTrackerPair {
+	shift := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		return t.Add(offset)
+	}
+	for id, record := range records {
+		record.ScheduledTime = shift(record.ScheduledTime)
+		record.StartTime = shift(record.StartTime)
+		record.SendEndTime = shift(record.SendEndTime)
+		records[id] = record
+	}
+	return records
+}
+
+func postJSON(ctx context.Context, url string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("controller responded with %s", res.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
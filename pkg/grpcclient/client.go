@@ -0,0 +1,159 @@
+// Package grpcclient calls unary grpc methods whose messages are only known
+// at runtime, from a descriptor set or the server's reflection service.
+package grpcclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// resolveTimeout bounds looking the method up through reflection.
+const resolveTimeout = 10 * time.Second
+
+// Response is what a call came back with. Body is the json encoded reply
+// and is empty unless Code is OK.
+type Response struct {
+	Code    codes.Code
+	Message string
+	Header  metadata.MD
+	Trailer metadata.MD
+	Body    []byte
+}
+
+// Client calls a single unary method.
+type Client struct {
+	conn   *grpc.ClientConn
+	path   string
+	method protoreflect.MethodDescriptor
+}
+
+// Dial connects to cfg.Address and resolves cfg.Method, either from
+// cfg.DescriptorSet or through server reflection.
+func Dial(cfg types.GRPCConfig) (*Client, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("Grpc address is missing")
+	}
+	service, method, err := splitMethod(cfg.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{ServerName: cfg.Authority})
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if cfg.Authority != "" {
+		opts = append(opts, grpc.WithAuthority(cfg.Authority))
+	}
+	conn, err := grpc.NewClient(cfg.Address, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files resolver
+	if cfg.DescriptorSet != "" {
+		files, err = loadDescriptorSet(cfg.DescriptorSet)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+		files, err = reflectService(ctx, conn, service)
+		cancel()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	md, err := findMethod(files, service, method)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Client{conn: conn, path: "/" + service + "/" + method, method: md}, nil
+}
+
+// Method is the full path of the method called, eg. /jobs.v1.Jobs/Start.
+func (c *Client) Method() string {
+	return c.path
+}
+
+// Invoke calls the method with the request message decoded from the json
+// body, header is sent as metadata. A non OK status is returned as the
+// error alongside the response.
+func (c *Client) Invoke(ctx context.Context, header http.Header, body []byte) (*Response, error) {
+	req := dynamicpb.NewMessage(c.method.Input())
+	if err := protojson.Unmarshal(body, req); err != nil {
+		return nil, errors.New("Body does not match " + string(c.method.Input().FullName()) + ": " + err.Error())
+	}
+	ctx = metadata.NewOutgoingContext(ctx, toMetadata(header))
+
+	reply := dynamicpb.NewMessage(c.method.Output())
+	res := &Response{}
+	err := c.conn.Invoke(ctx, c.path, req, reply, grpc.Header(&res.Header), grpc.Trailer(&res.Trailer))
+
+	st := status.Convert(err)
+	res.Code = st.Code()
+	res.Message = st.Message()
+	if err != nil {
+		return res, err
+	}
+	res.Body, err = protojson.Marshal(reply)
+	return res, err
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// splitMethod accepts package.Service/Method, with or without a leading
+// slash.
+func splitMethod(name string) (string, string, error) {
+	service, method, found := strings.Cut(strings.TrimPrefix(name, "/"), "/")
+	if !found || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", errors.New("Grpc method must look like package.Service/Method: " + name)
+	}
+	return service, method, nil
+}
+
+func findMethod(files resolver, service, method string) (protoreflect.MethodDescriptor, error) {
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, errors.New("Grpc service not found: " + service)
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, errors.New("Not a grpc service: " + service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, errors.New("Grpc method not found: " + service + "/" + method)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, errors.New("Grpc method is streaming, only unary methods can be called: " + service + "/" + method)
+	}
+	return md, nil
+}
+
+// toMetadata lowercases header names as grpc requires.
+func toMetadata(header http.Header) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range header {
+		md.Append(strings.ToLower(key), values...)
+	}
+	return md
+}
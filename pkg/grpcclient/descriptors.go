@@ -0,0 +1,120 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type resolver interface {
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+// loadDescriptorSet reads a FileDescriptorSet, it has to include the
+// imports of the files that declare the service.
+func loadDescriptorSet(path string) (resolver, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("Could not read descriptor set: " + path)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		return nil, errors.New("Invalid descriptor set: " + path)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, errors.New("Invalid descriptor set: " + err.Error())
+	}
+	return files, nil
+}
+
+// reflectService asks the server's reflection service for the file that
+// declares service and, one by one, for every file it imports.
+func reflectService(ctx context.Context, conn *grpc.ClientConn, service string) (resolver, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, errors.New("Server reflection failed: " + err.Error())
+	}
+	defer stream.CloseSend()
+
+	fetched := map[string]*descriptorpb.FileDescriptorProto{}
+	var order []string
+	add := func(fds []*descriptorpb.FileDescriptorProto) {
+		for _, fd := range fds {
+			if _, seen := fetched[fd.GetName()]; !seen {
+				fetched[fd.GetName()] = fd
+				order = append(order, fd.GetName())
+			}
+		}
+	}
+
+	fds, err := reflectFiles(stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	if err != nil {
+		return nil, err
+	}
+	add(fds)
+
+	// Servers usually send the imports along, ask for the ones they left out
+	for i := 0; i < len(order); i++ {
+		for _, dep := range fetched[order[i]].GetDependency() {
+			if _, seen := fetched[dep]; seen {
+				continue
+			}
+			fds, err := reflectFiles(stream, &rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				// Well known types are compiled in, servers may not serve them
+				known, found := protoregistry.GlobalFiles.FindFileByPath(dep)
+				if found != nil {
+					return nil, err
+				}
+				fds = []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(known)}
+			}
+			add(fds)
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, name := range order {
+		set.File = append(set.File, fetched[name])
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, errors.New("Invalid descriptors from server reflection: " + err.Error())
+	}
+	return files, nil
+}
+
+func reflectFiles(stream rpb.ServerReflection_ServerReflectionInfoClient, req *rpb.ServerReflectionRequest) ([]*descriptorpb.FileDescriptorProto, error) {
+	if err := stream.Send(req); err != nil {
+		return nil, errors.New("Server reflection failed: " + err.Error())
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, errors.New("Server reflection failed: " + err.Error())
+	}
+	if e := res.GetErrorResponse(); e != nil {
+		return nil, errors.New("Server reflection failed: " + e.GetErrorMessage())
+	}
+
+	var fds []*descriptorpb.FileDescriptorProto
+	for _, raw := range res.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		fd := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(raw, fd); err != nil {
+			return nil, errors.New("Invalid descriptor from server reflection: " + err.Error())
+		}
+		fds = append(fds, fd)
+	}
+	return fds, nil
+}
@@ -0,0 +1,43 @@
+package reporter
+
+import (
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+// MaxAssertionExamples bounds how many failure messages are kept per rule.
+var MaxAssertionExamples = 3
+
+type AssertionMetrics struct {
+	// FailedRequests had at least one callback breaking a rule
+	FailedRequests int
+	// ByRule counts the requests breaking each rule
+	ByRule   map[string]int
+	Examples map[string][]string
+}
+
+func newAssertionMetrics() AssertionMetrics {
+	return AssertionMetrics{
+		ByRule:   make(map[string]int),
+		Examples: make(map[string][]string),
+	}
+}
+
+// add counts the rules pair broke.
+func (m *AssertionMetrics) add(pair tracker.RequestTrackerPair) {
+	if len(pair.AssertionFailures) == 0 {
+		return
+	}
+	m.FailedRequests++
+
+	seen := make(map[string]bool)
+	for _, failure := range pair.AssertionFailures {
+		if seen[failure.Rule] {
+			continue
+		}
+		seen[failure.Rule] = true
+		m.ByRule[failure.Rule]++
+		if len(m.Examples[failure.Rule]) < MaxAssertionExamples {
+			m.Examples[failure.Rule] = append(m.Examples[failure.Rule], failure.Message)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+var csvHeader = []string{
+	"id", "scenario", "outcome",
+	"scheduled_time", "start_time", "send_end_time", "end_time",
+	"sync_latency_ms", "callback_latency_ms", "status_code",
+	"request_bytes", "response_bytes", "callback_bytes",
+	"attempts", "callbacks", "redeliveries", "duplicates",
+	"invalid_signature", "assertion_failures", "tags", "error",
+}
+
+// WriteCSVRecords writes a row per request, in the order records visits
+// them. Times are RFC 3339 and left empty when they never happened.
+func WriteCSVRecords(w io.Writer, records Records) error {
+	if records == nil {
+		return errors.New("Csv output needs the records of the run")
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+	var writeErr error
+	err := records.Range(func(key string, r tracker.RequestTrackerPair) bool {
+		writeErr = out.Write([]string{
+			key, r.Scenario, string(r.Outcome()),
+			csvTime(r.ScheduledTime), csvTime(r.StartTime), csvTime(r.SendEndTime), csvTime(r.EndTime),
+			csvMilliseconds(r.SyncLatency()), csvMilliseconds(r.CallbackLatency()), strconv.Itoa(r.StatusCode),
+			strconv.Itoa(r.RequestBytes), strconv.Itoa(r.ResponseBytes), strconv.Itoa(r.CallbackBytes),
+			strconv.Itoa(r.AttemptCount()), strconv.Itoa(r.CallbackCount()), strconv.Itoa(len(r.Redeliveries)), strconv.Itoa(r.Duplicates),
+			strconv.FormatBool(r.InvalidSignature), strconv.Itoa(len(r.AssertionFailures)), csvTags(r.Tags), r.Error,
+		})
+		return writeErr == nil
+	})
+	out.Flush()
+	return errors.Join(err, writeErr, out.Error())
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func csvMilliseconds(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(milliseconds(d), 'f', 3, 64)
+}
+
+// csvTags joins tags as key=value pairs, sorted by key.
+func csvTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
@@ -0,0 +1,36 @@
+package reporter
+
+import (
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+// MaxDisconnectionSamples bounds how many dropped connections are listed in
+// reports.
+var MaxDisconnectionSamples = 5
+
+type DisconnectionMetrics struct {
+	Total         int
+	Unrecovered   int
+	TotalDowntime time.Duration
+	MaxDowntime   time.Duration
+	Samples       []tracker.Disconnection
+}
+
+// CalculateDisconnectionMetrics sums up the drops of a stream receiver and
+// keeps the first few of them as samples.
+func CalculateDisconnectionMetrics(disconnections []tracker.Disconnection) DisconnectionMetrics {
+	m := DisconnectionMetrics{Total: len(disconnections)}
+	for _, d := range disconnections {
+		if !d.Recovered {
+			m.Unrecovered++
+		}
+		m.TotalDowntime += d.Downtime
+		m.MaxDowntime = max(m.MaxDowntime, d.Downtime)
+		if len(m.Samples) < MaxDisconnectionSamples {
+			m.Samples = append(m.Samples, d)
+		}
+	}
+	return m
+}
@@ -0,0 +1,10 @@
+// switches between the tabs of the report
+document.querySelectorAll(".tabs button").forEach(function (button) {
+  button.addEventListener("click", function () {
+    document.querySelectorAll(".tabs button, .tab").forEach(function (el) {
+      el.classList.remove("active");
+    });
+    button.classList.add("active");
+    document.getElementById(button.dataset.tab).classList.add("active");
+  });
+});
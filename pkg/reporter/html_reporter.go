@@ -0,0 +1,494 @@
+package reporter
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+//go:embed html
+var htmlAssets embed.FS
+
+var htmlTemplate = template.Must(template.New("report.html.tmpl").Funcs(template.FuncMap{
+	"latency": roundLatency,
+	"percent": percent,
+}).ParseFS(htmlAssets, "html/report.html.tmpl"))
+
+// MaxHTMLErrors bounds how many distinct error messages a tab lists.
+var MaxHTMLErrors = 10
+
+// defaultScenario names the tab of records without a scenario.
+const defaultScenario = "default"
+
+type htmlPage struct {
+	Title     string
+	Generated time.Time
+	Style     template.CSS
+	Script    template.JS
+	Settings  []htmlRow
+	Orphans   OrphanMetrics
+	Drops     DisconnectionMetrics
+	Tabs      []htmlTab
+}
+
+type htmlTab struct {
+	ID          string
+	Name        string
+	Metrics     Metrics
+	Percentiles []htmlPercentile
+	Outcomes    []htmlRow
+	StatusCodes []htmlRow
+	Errors      []htmlRow
+	Rules       []htmlRow
+
+	LatencyBySend    template.HTML
+	LatencyByArrival template.HTML
+	Throughput       template.HTML
+	Histogram        template.HTML
+}
+
+type htmlPercentile struct {
+	Label    string
+	Callback time.Duration
+	Sync     time.Duration
+}
+
+type htmlRow struct {
+	Label string
+	Value string
+	// Share is the part of every request Value stands for, when it counts
+	// requests
+	Share string
+}
+
+func percent(part, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return strconv.FormatFloat(100*float64(part)/float64(total), 'f', 1, 64) + "%"
+}
+
+// scenarioSamples are the latencies and errors of a scenario, kept while
+// visiting the records to draw the histogram and percentile table.
+type scenarioSamples struct {
+	records   int
+	callbacks latencySketch
+	syncs     latencySketch
+	errors    map[string]int
+}
+
+func (s *scenarioSamples) add(pair tracker.RequestTrackerPair) {
+	s.records++
+	if !pair.EndTime.IsZero() {
+		s.callbacks.add(pair.CallbackLatency())
+	}
+	if !pair.SendEndTime.IsZero() {
+		s.syncs.add(pair.SyncLatency())
+	}
+	if pair.Error != "" {
+		s.errors[pair.Error]++
+	}
+}
+
+// scenarioRecords are the records of one scenario.
+type scenarioRecords struct {
+	records  Records
+	scenario string
+	len      int
+}
+
+func (s scenarioRecords) Len() int {
+	return s.len
+}
+
+func (s scenarioRecords) Range(fn func(key string, value tracker.RequestTrackerPair) bool) error {
+	return s.records.Range(func(key string, value tracker.RequestTrackerPair) bool {
+		if scenarioOf(value) != s.scenario {
+			return true
+		}
+		return fn(key, value)
+	})
+}
+
+func scenarioOf(pair tracker.RequestTrackerPair) string {
+	if pair.Scenario == "" {
+		return defaultScenario
+	}
+	return pair.Scenario
+}
+
+// WriteHTMLReport writes a single html file with everything needed to
+// view it inlined: charts of the latency over time, a latency histogram,
+// percentiles, a breakdown of the errors and a summary of the config. Runs
+// with several scenarios get a tab for each. Histograms and percentiles
+// need report.Records, only the time series is charted without them.
+func WriteHTMLReport(w io.Writer, report Report) error {
+	style, err := htmlAssets.ReadFile("html/report.css")
+	if err != nil {
+		return err
+	}
+	script, err := htmlAssets.ReadFile("html/report.js")
+	if err != nil {
+		return err
+	}
+
+	m := report.Metrics
+	page := htmlPage{
+		Title:     "Webhook load test report",
+		Generated: time.Now(),
+		Style:     template.CSS(style),
+		Script:    template.JS(script),
+		Settings:  configSettings(report.Config),
+		Orphans:   m.Orphans,
+		Drops:     m.Disconnections,
+	}
+	if report.Config != nil && report.Config.Test.Name != "" {
+		page.Title += ": " + report.Config.Test.Name
+	}
+
+	if report.Records == nil {
+		page.Tabs = []htmlTab{newHTMLTab("all", "Run", m, nil)}
+		return htmlTemplate.Execute(w, page)
+	}
+
+	all := &scenarioSamples{errors: map[string]int{}}
+	scenarios := map[string]*scenarioSamples{}
+	err = report.Records.Range(func(_ string, pair tracker.RequestTrackerPair) bool {
+		all.add(pair)
+		name := scenarioOf(pair)
+		if scenarios[name] == nil {
+			scenarios[name] = &scenarioSamples{errors: map[string]int{}}
+		}
+		scenarios[name].add(pair)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) <= 1 {
+		name := "Run"
+		if len(names) == 1 {
+			name = names[0]
+		}
+		page.Tabs = []htmlTab{newHTMLTab("all", name, m, all)}
+		return htmlTemplate.Execute(w, page)
+	}
+
+	page.Tabs = []htmlTab{newHTMLTab("all", "All scenarios", m, all)}
+	for i, name := range names {
+		records := scenarioRecords{records: report.Records, scenario: name, len: scenarios[name].records}
+		scenarioMetrics, err := CalculateStoreMetrics(records, m.TotalDuration, m.TimeSeries.Interval)
+		if err != nil {
+			return err
+		}
+		page.Tabs = append(page.Tabs, newHTMLTab(fmt.Sprintf("scenario-%d", i+1), name, scenarioMetrics, scenarios[name]))
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+func newHTMLTab(id, name string, m Metrics, samples *scenarioSamples) htmlTab {
+	tab := htmlTab{ID: id, Name: name, Metrics: m}
+
+	for _, outcome := range tracker.Outcomes {
+		if count := m.Outcomes[outcome]; count > 0 {
+			tab.Outcomes = append(tab.Outcomes, htmlRow{Label: string(outcome), Value: strconv.Itoa(count), Share: percent(count, m.TotalRequests)})
+		}
+	}
+	codes := make([]int, 0, len(m.StatusCodes))
+	for code := range m.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		tab.StatusCodes = append(tab.StatusCodes, htmlRow{Label: strconv.Itoa(code), Value: strconv.Itoa(m.StatusCodes[code])})
+	}
+	rules := make([]string, 0, len(m.Assertions.ByRule))
+	for rule := range m.Assertions.ByRule {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		tab.Rules = append(tab.Rules, htmlRow{Label: rule, Value: strconv.Itoa(m.Assertions.ByRule[rule])})
+	}
+
+	ts := m.TimeSeries
+	tab.LatencyBySend = latencyChart(ts, ts.BySendTime)
+	tab.LatencyByArrival = latencyChart(ts, ts.ByArrivalTime)
+	tab.Throughput = throughputChart(ts)
+
+	if samples == nil {
+		return tab
+	}
+	for _, p := range []struct {
+		label string
+		p     float64
+	}{{"p50", 0.50}, {"p75", 0.75}, {"p90", 0.90}, {"p95", 0.95}, {"p99", 0.99}, {"p99.9", 0.999}, {"max", 1}} {
+		tab.Percentiles = append(tab.Percentiles, htmlPercentile{
+			Label:    p.label,
+			Callback: samples.callbacks.quantile(p.p),
+			Sync:     samples.syncs.quantile(p.p),
+		})
+	}
+	tab.Histogram = histogramChart(&samples.callbacks)
+	tab.Errors = topErrors(samples.errors)
+	return tab
+}
+
+// topErrors lists the most frequent error messages first.
+func topErrors(errors map[string]int) []htmlRow {
+	messages := make([]string, 0, len(errors))
+	for message := range errors {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if errors[messages[i]] != errors[messages[j]] {
+			return errors[messages[i]] > errors[messages[j]]
+		}
+		return messages[i] < messages[j]
+	})
+
+	counts := make([]htmlRow, 0, min(len(messages), MaxHTMLErrors))
+	for _, message := range messages[:min(len(messages), MaxHTMLErrors)] {
+		counts = append(counts, htmlRow{Label: truncate(message, 200), Value: strconv.Itoa(errors[message])})
+	}
+	return counts
+}
+
+// configSettings summarises what was run. Secrets, headers and bodies are
+// left out so the report can be shared.
+func configSettings(config *types.InputConfig) []htmlRow {
+	if config == nil {
+		return nil
+	}
+	orDefault := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+
+	test, run := config.Test, config.Run
+	settings := []htmlRow{
+		{Label: "Test", Value: orDefault(test.Name, "-")},
+		{Label: "Trigger", Value: orDefault(test.Trigger, "http")},
+	}
+	switch {
+	case test.GRPC.Address != "":
+		settings = append(settings, htmlRow{Label: "Target", Value: test.GRPC.Address + " " + test.GRPC.Method})
+	case test.Broker.Topic != "":
+		settings = append(settings, htmlRow{Label: "Target", Value: test.Broker.Topic})
+	case test.URL != "":
+		settings = append(settings, htmlRow{Label: "Target", Value: test.URL})
+	}
+	if len(test.Tags) > 0 {
+		settings = append(settings, htmlRow{Label: "Tags", Value: csvTags(test.Tags)})
+	}
+	settings = append(settings,
+		htmlRow{Label: "Iterations", Value: strconv.Itoa(run.Iterations)},
+		htmlRow{Label: "Duration", Value: (time.Duration(run.DurationSeconds) * time.Second).String()},
+		htmlRow{Label: "Arrival", Value: orDefault(run.Arrival.Type, "constant")},
+	)
+	if run.MaxInFlight > 0 {
+		settings = append(settings, htmlRow{Label: "Max in flight", Value: strconv.Itoa(run.MaxInFlight) + " (" + orDefault(run.InFlightPolicy, "block") + ")"})
+	}
+	settings = append(settings,
+		htmlRow{Label: "Callback timeout", Value: (time.Duration(test.Timeout) * time.Second).String()},
+		htmlRow{Label: "Receiver", Value: orDefault(config.Receiver.Type, "http")},
+	)
+	if len(test.Expect) > 0 {
+		settings = append(settings, htmlRow{Label: "Expectations", Value: strconv.Itoa(len(test.Expect))})
+	}
+	if config.Receiver.Signature.Scheme != "" {
+		settings = append(settings, htmlRow{Label: "Signature", Value: config.Receiver.Signature.Scheme})
+	}
+	return settings
+}
+
+const (
+	chartWidth  = 760
+	chartHeight = 240
+	chartLeft   = 70
+	chartRight  = 20
+	chartTop    = 20
+	chartBottom = 40
+)
+
+type chartSeries struct {
+	name   string
+	color  string
+	values []float64
+}
+
+func latencyChart(ts TimeSeries, buckets []Bucket) template.HTML {
+	median, p95, p99 := make([]float64, len(buckets)), make([]float64, len(buckets)), make([]float64, len(buckets))
+	for i, b := range buckets {
+		median[i], p95[i], p99[i] = milliseconds(b.MedianLatency), milliseconds(b.P95Latency), milliseconds(b.P99Latency)
+	}
+	return lineChart(bucketLabels(ts, buckets), "ms", []chartSeries{
+		{"p50", "#2f7ed8", median},
+		{"p95", "#f28f43", p95},
+		{"p99", "#c42525", p99},
+	})
+}
+
+func throughputChart(ts TimeSeries) template.HTML {
+	buckets := ts.ByArrivalTime
+	sends, callbacks, errors := make([]float64, len(buckets)), make([]float64, len(buckets)), make([]float64, len(buckets))
+	for i, b := range buckets {
+		sends[i], callbacks[i], errors[i] = float64(b.Sends), float64(b.Callbacks), float64(b.Errors)
+	}
+	return lineChart(bucketLabels(ts, buckets), "", []chartSeries{
+		{"sends", "#2f7ed8", sends},
+		{"callbacks", "#3c9d4e", callbacks},
+		{"errors", "#c42525", errors},
+	})
+}
+
+func bucketLabels(ts TimeSeries, buckets []Bucket) []string {
+	labels := make([]string, len(buckets))
+	for i, b := range buckets {
+		labels[i] = "+" + b.Start.Sub(buckets[0].Start).String()
+	}
+	return labels
+}
+
+// lineChart draws series over a shared x axis as an inline svg, every
+// point carries a tooltip with its value.
+func lineChart(labels []string, unit string, series []chartSeries) template.HTML {
+	if len(labels) == 0 {
+		return ""
+	}
+	top := 0.0
+	for _, s := range series {
+		for _, v := range s.values {
+			top = max(top, v)
+		}
+	}
+	top = niceCeiling(top)
+
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	x := func(i int) float64 {
+		if len(labels) == 1 {
+			return chartLeft + plotWidth/2
+		}
+		return chartLeft + plotWidth*float64(i)/float64(len(labels)-1)
+	}
+	y := func(v float64) float64 {
+		return chartTop + plotHeight - plotHeight*v/top
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg class="chart" viewBox="0 0 %d %d" role="img">`, chartWidth, chartHeight)
+	drawYAxis(&svg, top, unit, y)
+	step := max(1, len(labels)/8)
+	for i := 0; i < len(labels); i += step {
+		fmt.Fprintf(&svg, `<text class="tick" x="%.1f" y="%d" text-anchor="middle">%s</text>`, x(i), chartHeight-chartBottom+18, template.HTMLEscapeString(labels[i]))
+	}
+
+	for _, s := range series {
+		points := make([]string, len(s.values))
+		for i, v := range s.values {
+			points[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(v))
+		}
+		fmt.Fprintf(&svg, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, s.color, strings.Join(points, " "))
+		for i, v := range s.values {
+			fmt.Fprintf(&svg, `<circle cx="%.1f" cy="%.1f" r="2.5" fill="%s"><title>%s %s: %s%s</title></circle>`,
+				x(i), y(v), s.color, template.HTMLEscapeString(labels[i]), s.name, formatValue(round2(v)), unit)
+		}
+	}
+	for i, s := range series {
+		fmt.Fprintf(&svg, `<rect x="%d" y="4" width="10" height="10" fill="%s"/><text class="legend" x="%d" y="13">%s</text>`,
+			chartLeft+i*90, s.color, chartLeft+i*90+14, s.name)
+	}
+	svg.WriteString(`</svg>`)
+	return template.HTML(svg.String())
+}
+
+// histogramBins is how many bars the latency histogram has.
+const histogramBins = 20
+
+// histogramChart draws how the latencies are spread as bars.
+func histogramChart(latencies *latencySketch) template.HTML {
+	if latencies.count == 0 {
+		return ""
+	}
+	top := niceCeiling(milliseconds(latencies.max))
+	width := top / histogramBins
+	counts := make([]float64, histogramBins)
+	latencies.each(func(latency time.Duration, count int) bool {
+		bin := int(milliseconds(latency) / width)
+		counts[max(0, min(bin, histogramBins-1))] += float64(count)
+		return true
+	})
+	highest := niceCeiling(slices.Max(counts))
+
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	y := func(v float64) float64 {
+		return chartTop + plotHeight - plotHeight*v/highest
+	}
+	barWidth := plotWidth / histogramBins
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg class="chart" viewBox="0 0 %d %d" role="img">`, chartWidth, chartHeight)
+	drawYAxis(&svg, highest, "", y)
+	for i, count := range counts {
+		from, to := width*float64(i), width*float64(i+1)
+		left := chartLeft + barWidth*float64(i)
+		fmt.Fprintf(&svg, `<rect class="bar" x="%.1f" y="%.1f" width="%.1f" height="%.1f"><title>%s-%sms: %d</title></rect>`,
+			left+1, y(count), barWidth-2, chartTop+plotHeight-y(count), formatValue(round2(from)), formatValue(round2(to)), int(count))
+		if i%4 == 0 {
+			fmt.Fprintf(&svg, `<text class="tick" x="%.1f" y="%d" text-anchor="middle">%sms</text>`, left, chartHeight-chartBottom+18, formatValue(round2(from)))
+		}
+	}
+	svg.WriteString(`</svg>`)
+	return template.HTML(svg.String())
+}
+
+// drawYAxis draws five gridlines from 0 to top.
+func drawYAxis(svg *strings.Builder, top float64, unit string, y func(float64) float64) {
+	for i := 0; i <= 4; i++ {
+		v := top * float64(i) / 4
+		fmt.Fprintf(svg, `<line class="grid" x1="%d" x2="%d" y1="%.1f" y2="%.1f"/>`, chartLeft, chartWidth-chartRight, y(v), y(v))
+		fmt.Fprintf(svg, `<text class="tick" x="%d" y="%.1f" text-anchor="end">%s%s</text>`, chartLeft-6, y(v)+4, formatValue(round2(v)), unit)
+	}
+}
+
+// niceCeiling rounds v up to 1, 2 or 5 times a power of ten, so axis
+// ticks are round numbers. It is never below 1.
+func niceCeiling(v float64) float64 {
+	if v <= 1 {
+		return 1
+	}
+	magnitude := 1.0
+	for magnitude*10 <= v {
+		magnitude *= 10
+	}
+	for _, step := range []float64{1, 2, 5, 10} {
+		if step*magnitude >= v {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func round2(v float64) float64 {
+	return float64(int64(v*100+0.5)) / 100
+}
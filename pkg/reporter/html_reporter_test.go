@@ -0,0 +1,74 @@
+package reporter
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	var pairs []tracker.RequestTrackerPair
+	for i := 0; i < 40; i++ {
+		sent := start.Add(time.Duration(i) * 100 * time.Millisecond)
+		pair := tracker.RequestTrackerPair{
+			StartTime:   sent,
+			SendEndTime: sent.Add(20 * time.Millisecond),
+			EndTime:     sent.Add(time.Duration(100+10*i) * time.Millisecond),
+			StatusCode:  202,
+			Scenario:    "start-job",
+		}
+		if i%2 == 1 {
+			pair.Scenario = "cancel-job"
+		}
+		if i%10 == 0 {
+			pair.EndTime = time.Time{}
+			pair.Error = "<script>alert(1)</script>"
+		}
+		pairs = append(pairs, pair)
+	}
+	records := pairRecords(pairs)
+	metrics := CalculateMetrics(pairs, 4*time.Second)
+	config := &types.InputConfig{}
+	config.Test.Name = "jobs"
+	config.Receiver.Signature = types.SignatureConfig{Scheme: "hmac", Secret: "s3cret"}
+
+	var out bytes.Buffer
+	if err := WriteHTMLReport(&out, Report{Metrics: metrics, Records: records, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	page := out.String()
+
+	for _, want := range []string{
+		"<title>Webhook load test report: jobs</title>",
+		`data-tab="all"`, ">All scenarios</button>", ">cancel-job</button>", ">start-job</button>",
+		"Callback latency by send time", "Callback latency histogram", "<th>p99</th>",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected %q in the report", want)
+		}
+	}
+	if strings.Contains(page, "s3cret") || strings.Contains(page, "<script>alert(1)") {
+		t.Error("Expected secrets to be left out and errors to be escaped")
+	}
+	// everything is inlined, nothing is fetched when viewing it
+	if regexp.MustCompile(`(src|href)=`).MatchString(page) {
+		t.Error("Expected a self-contained report")
+	}
+}
+
+func TestWriteHTMLReport_WithoutRecords(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteHTMLReport(&out, Report{Metrics: Metrics{TotalRequests: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), `class="tabs"`) {
+		t.Error("Expected a single tab without a tab bar")
+	}
+}
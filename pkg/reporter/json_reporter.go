@@ -0,0 +1,13 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteJSONMetrics writes m as indented json. Durations are nanoseconds.
+func WriteJSONMetrics(w io.Writer, m Metrics) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
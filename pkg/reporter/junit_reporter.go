@@ -0,0 +1,91 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemOut *junitOutput    `xml:"system-out"`
+}
+
+// junitOutput keeps the newlines of the text report readable.
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// WriteJUnitReport writes a junit test case per threshold, so CI test
+// dashboards show which bounds the run broke. DefaultThresholds are
+// checked when none are configured. The text report goes in system-out.
+func WriteJUnitReport(w io.Writer, report Report) error {
+	m := report.Metrics
+	results := m.Thresholds
+	if len(results) == 0 {
+		results = EvaluateThresholds(m, DefaultThresholds)
+	}
+
+	name := "webhook-load-tester"
+	if report.Config != nil && report.Config.Test.Name != "" {
+		name = report.Config.Test.Name
+	}
+	seconds := strconv.FormatFloat(m.TotalDuration.Seconds(), 'f', 3, 64)
+
+	var text bytes.Buffer
+	PrintTextMetrics(&text, m)
+	suite := junitTestSuite{Name: name, Time: seconds, SystemOut: &junitOutput{text.String()}}
+	for _, result := range results {
+		testCase := junitTestCase{Name: result.Name, Classname: name, Time: "0"}
+		if !result.Passed {
+			testCase.Failure = &junitFailure{Message: result.describe(), Type: "threshold"}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+	}
+
+	suites := junitTestSuites{
+		Name:     name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     seconds,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,39 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+func TestWriteJUnitReport_DefaultFailsTimedOutRequests(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	pairs := []tracker.RequestTrackerPair{
+		{StartTime: start, SendEndTime: start.Add(10 * time.Millisecond), EndTime: start.Add(time.Second)},
+		// sent fine but never called back
+		{StartTime: start, SendEndTime: start.Add(10 * time.Millisecond)},
+	}
+
+	var out bytes.Buffer
+	if err := WriteJUnitReport(&out, Report{Metrics: CalculateMetrics(pairs, 2*time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Cases []struct {
+				Name string `xml:"name,attr"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("Invalid junit output: %v", err)
+	}
+	if suites.Tests != 1 || suites.Failures != 1 || suites.Suites[0].Cases[0].Name != "nonOkRequests <= 0" {
+		t.Errorf("Expected the timed out request to fail the default threshold, got\n%s", out.String())
+	}
+}
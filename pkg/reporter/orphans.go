@@ -0,0 +1,29 @@
+package reporter
+
+import (
+	"maps"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+type OrphanMetrics struct {
+	Total    int
+	ByReason map[string]int
+	Samples  []tracker.Orphan
+}
+
+// CalculateOrphanMetrics totals the orphan callbacks counted per reason.
+// Samples are the few a store kept whole, see tracker.MaxOrphanSamples.
+func CalculateOrphanMetrics(counts map[string]int, samples []tracker.Orphan) OrphanMetrics {
+	m := OrphanMetrics{
+		ByReason: maps.Clone(counts),
+		Samples:  samples,
+	}
+	if m.ByReason == nil {
+		m.ByReason = make(map[string]int)
+	}
+	for _, count := range counts {
+		m.Total += count
+	}
+	return m
+}
@@ -4,8 +4,8 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/jamiealquiza/tachymeter"
 	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
 )
 
 type Metrics struct {
@@ -17,32 +17,226 @@ type Metrics struct {
 	MedianResponseTime  time.Duration
 	Percentile95Time    time.Duration
 	RequestsPerSecond   float64
+	SkippedRequests     int
+	AverageScheduleLag  time.Duration
+	MaxScheduleLag      time.Duration
+	FailedRequests      int
+	// Outcomes counts requests by how they ended
+	Outcomes map[tracker.Outcome]int
+	// StatusCodes counts responses per http status, for runs that record them
+	StatusCodes         map[int]int
+	InvalidSignatures   int
+	CallbackAttempts    int
+	RejectedAttempts    int
+	RetriedRequests     int
+	AverageRetryDelay   time.Duration
+	MaxRetryDelay       time.Duration
+	Redeliveries        int
+	DuplicateDeliveries int
+	// DuplicateRate is the share of completed requests delivered more than once
+	DuplicateRate               float64
+	MinRedeliveryDelay          time.Duration
+	AverageRedeliveryDelay      time.Duration
+	MedianRedeliveryDelay       time.Duration
+	Percentile95RedeliveryDelay time.Duration
+	MaxRedeliveryDelay          time.Duration
+	Orphans                     OrphanMetrics
+	Assertions                  AssertionMetrics
+	// Disconnections are the drops of stream receivers
+	Disconnections DisconnectionMetrics
+
+	// Sync latency is how long triggers took to return
+	AverageSyncLatency      time.Duration
+	Percentile95SyncLatency time.Duration
+	MaxSyncLatency          time.Duration
+
+	// TimeSeries breaks the run down per interval
+	TimeSeries TimeSeries
+	// Thresholds are the configured bounds and whether the run met them
+	Thresholds []ThresholdResult
+}
+
+// Report is what outputs are written from.
+type Report struct {
+	Metrics Metrics
+	// Records are the requests of the run, for outputs listing them one by
+	// one. Nil when they weren't kept.
+	Records Records
+	// Config of the run, nil when unknown
+	Config *types.InputConfig
+}
+
+// Records is a source of records visited one at a time, such as a
+// tracker.Store.
+type Records interface {
+	Len() int
+	Range(fn func(key string, value tracker.RequestTrackerPair) bool) error
+}
+
+type pairRecords []tracker.RequestTrackerPair
+
+func (p pairRecords) Len() int {
+	return len(p)
+}
+
+func (p pairRecords) Range(fn func(key string, value tracker.RequestTrackerPair) bool) error {
+	for _, pair := range p {
+		if !fn("", pair) {
+			break
+		}
+	}
+	return nil
 }
 
 // CalculateMetrics calculates the desired metrics from an array of RequestTrackerPair
 func CalculateMetrics(pairs []tracker.RequestTrackerPair, totalDuration time.Duration) Metrics {
-	totalRequests := len(pairs)
+	metrics, _ := CalculateStoreMetrics(pairRecords(pairs), totalDuration, DefaultBucketInterval)
+	return metrics
+}
+
+// CalculateStoreMetrics is CalculateMetrics for records that don't fit in
+// memory. They are visited once and their latencies are summarised in
+// sketches of bounded size, so memory grows with the number of time series
+// buckets rather than with the number of records. Percentiles of large
+// runs are within 1% of the real value. The time series is split in
+// buckets of bucketInterval, DefaultBucketInterval when it is 0.
+func CalculateStoreMetrics(records Records, totalDuration time.Duration, bucketInterval time.Duration) (Metrics, error) {
+	totalRequests := records.Len()
 	slog.Debug("Total Requests: ", "req", totalRequests)
 	if totalRequests == 0 {
-		return Metrics{}
+		return Metrics{}, nil
 	}
 
-	t := tachymeter.New(&tachymeter.Config{Size: totalRequests})
+	var latencies, syncLatencies, redeliveries latencySketch
+
+	skipped := 0
+	fired := 0
+	var totalLag, maxLag time.Duration
+	failed := 0
+	outcomes := map[tracker.Outcome]int{}
+	statusCodes := map[int]int{}
+	invalidSignatures := 0
+	attempts, rejected, retried, retries := 0, 0, 0, 0
+	var totalRetryDelay, maxRetryDelay time.Duration
+	completed, duplicated, duplicates := 0, 0, 0
+	assertions := newAssertionMetrics()
+	timeSeries := newTimeSeriesBuilder(bucketInterval)
+	err := records.Range(func(_ string, pair tracker.RequestTrackerPair) bool {
+		outcomes[pair.Outcome()]++
+		assertions.add(pair)
+		timeSeries.add(pair)
+		if pair.Skipped {
+			skipped++
+			return true
+		}
+		if pair.Error != "" {
+			failed++
+		}
+		if pair.InvalidSignature {
+			invalidSignatures++
+		}
+		if pair.StatusCode != 0 {
+			statusCodes[pair.StatusCode]++
+		}
+		if !pair.SendEndTime.IsZero() {
+			syncLatencies.add(pair.SyncLatency())
+		}
 
-	for _, pair := range pairs {
-		t.AddTime(pair.EndTime.Sub(pair.StartTime))
+		attempts += len(pair.Attempts)
+		if len(pair.Attempts) > 1 {
+			retried++
+		}
+		for i, attempt := range pair.Attempts {
+			if attempt.Status < 200 || attempt.Status >= 300 {
+				rejected++
+			}
+			if i > 0 {
+				// gap the provider waited before retrying
+				delay := attempt.Time.Sub(pair.Attempts[i-1].Time)
+				retries++
+				totalRetryDelay += delay
+				maxRetryDelay = max(maxRetryDelay, delay)
+			}
+		}
+		// requests that never got a callback have no latency to speak of
+		if !pair.EndTime.IsZero() {
+			latencies.add(pair.CallbackLatency())
+			completed++
+		}
+		if pair.Duplicates > 0 {
+			duplicated++
+			duplicates += pair.Duplicates
+		}
+		for _, redelivery := range pair.Redeliveries {
+			redeliveries.add(redelivery.Sub(pair.EndTime))
+		}
+
+		if !pair.ScheduledTime.IsZero() {
+			lag := pair.StartTime.Sub(pair.ScheduledTime)
+			totalLag += lag
+			maxLag = max(maxLag, lag)
+			fired++
+		}
+		return true
+	})
+	if err != nil {
+		return Metrics{}, err
 	}
 
-	results := t.Calc()
+	// the rate of a single caller waiting for every callback in turn
+	var requestsPerSecond float64
+	if latencies.sum > 0 {
+		requestsPerSecond = float64(latencies.count) / latencies.sum.Seconds()
+	}
 
-	return Metrics{
-		TotalRequests:       totalRequests,
-		TotalDuration:       totalDuration,
-		AverageResponseTime: results.Time.Avg,
-		MinResponseTime:     results.Time.Min,
-		MaxResponseTime:     results.Time.Max,
-		MedianResponseTime:  results.Time.P50,
-		Percentile95Time:    results.Time.P95,
-		RequestsPerSecond:   results.Rate.Second,
+	var avgLag time.Duration
+	if fired > 0 {
+		avgLag = totalLag / time.Duration(fired)
 	}
+
+	var avgRetryDelay time.Duration
+	if retries > 0 {
+		avgRetryDelay = totalRetryDelay / time.Duration(retries)
+	}
+
+	var duplicateRate float64
+	if completed > 0 {
+		duplicateRate = float64(duplicated) / float64(completed)
+	}
+
+	return Metrics{
+		TotalRequests:               totalRequests,
+		TotalDuration:               totalDuration,
+		AverageResponseTime:         latencies.mean(),
+		MinResponseTime:             latencies.min,
+		MaxResponseTime:             latencies.max,
+		MedianResponseTime:          latencies.quantile(0.50),
+		Percentile95Time:            latencies.quantile(0.95),
+		RequestsPerSecond:           requestsPerSecond,
+		SkippedRequests:             skipped,
+		AverageScheduleLag:          avgLag,
+		MaxScheduleLag:              maxLag,
+		FailedRequests:              failed,
+		Outcomes:                    outcomes,
+		StatusCodes:                 statusCodes,
+		AverageSyncLatency:          syncLatencies.mean(),
+		Percentile95SyncLatency:     syncLatencies.quantile(0.95),
+		MaxSyncLatency:              syncLatencies.max,
+		InvalidSignatures:           invalidSignatures,
+		CallbackAttempts:            attempts,
+		RejectedAttempts:            rejected,
+		RetriedRequests:             retried,
+		AverageRetryDelay:           avgRetryDelay,
+		MaxRetryDelay:               maxRetryDelay,
+		Redeliveries:                redeliveries.count,
+		DuplicateDeliveries:         duplicates,
+		DuplicateRate:               duplicateRate,
+		MinRedeliveryDelay:          redeliveries.min,
+		AverageRedeliveryDelay:      redeliveries.mean(),
+		MedianRedeliveryDelay:       redeliveries.quantile(0.50),
+		Percentile95RedeliveryDelay: redeliveries.quantile(0.95),
+		MaxRedeliveryDelay:          redeliveries.max,
+		Assertions:                  assertions,
+		TimeSeries:                  timeSeries.build(),
+	}, nil
 }
@@ -0,0 +1,145 @@
+package reporter
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// exactSamples is how many durations a sketch keeps as they are. Past that
+// they are only counted in buckets.
+const exactSamples = 128
+
+// sketchGrowth is the ratio between the bounds of consecutive buckets, so
+// quantiles read from buckets are within 1% of the real value.
+const sketchGrowth = 1.02
+
+// zeroBucket counts durations below a nanosecond, eg. latencies made
+// negative by clock skew.
+const zeroBucket = math.MinInt
+
+// latencySketch summarises durations in bounded memory. Count, sum, min
+// and max are exact. Quantiles are exact until more than exactSamples were
+// added, then the durations are folded into logarithmic buckets: however
+// many durations come, only one count per bucket they fall in is kept.
+type latencySketch struct {
+	count    int
+	sum      time.Duration
+	min, max time.Duration
+
+	samples []time.Duration
+	sorted  bool
+	// buckets is nil while samples are kept
+	buckets map[int]int
+}
+
+func (s *latencySketch) add(d time.Duration) {
+	if s.count == 0 || d < s.min {
+		s.min = d
+	}
+	if s.count == 0 || d > s.max {
+		s.max = d
+	}
+	s.count++
+	s.sum += d
+
+	if s.buckets != nil {
+		s.buckets[bucketOf(d)]++
+		return
+	}
+	s.samples = append(s.samples, d)
+	s.sorted = false
+	if len(s.samples) > exactSamples {
+		s.buckets = make(map[int]int)
+		for _, sample := range s.samples {
+			s.buckets[bucketOf(sample)]++
+		}
+		s.samples = nil
+	}
+}
+
+func bucketOf(d time.Duration) int {
+	if d < 1 {
+		return zeroBucket
+	}
+	return int(math.Ceil(math.Log(float64(d)) / math.Log(sketchGrowth)))
+}
+
+// valueOf is the middle of a bucket, within 1% of everything in it.
+func valueOf(bucket int) time.Duration {
+	if bucket == zeroBucket {
+		return 0
+	}
+	upper := math.Pow(sketchGrowth, float64(bucket))
+	return time.Duration(2 * upper / (1 + sketchGrowth))
+}
+
+func (s *latencySketch) mean() time.Duration {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / time.Duration(s.count)
+}
+
+// quantile picks the nearest rank p of the durations, 0 when there are
+// none.
+func (s *latencySketch) quantile(p float64) time.Duration {
+	if s.count == 0 {
+		return 0
+	}
+	if s.buckets == nil {
+		s.sortSamples()
+		return percentile(s.samples, p)
+	}
+
+	rank := max(1, int(math.Ceil(float64(s.count)*p)))
+	seen := 0
+	value := s.max
+	s.each(func(v time.Duration, count int) bool {
+		seen += count
+		if seen >= rank {
+			value = v
+			return false
+		}
+		return true
+	})
+	// buckets only approximate, the ends are known exactly
+	return max(s.min, min(value, s.max))
+}
+
+// each calls fn with every duration, or the middle of every bucket and
+// how many durations fell in it, shortest first until fn returns false.
+func (s *latencySketch) each(fn func(value time.Duration, count int) bool) {
+	if s.buckets == nil {
+		s.sortSamples()
+		for _, sample := range s.samples {
+			if !fn(sample, 1) {
+				return
+			}
+		}
+		return
+	}
+	keys := make([]int, 0, len(s.buckets))
+	for key := range s.buckets {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !fn(valueOf(key), s.buckets[key]) {
+			return
+		}
+	}
+}
+
+func (s *latencySketch) sortSamples() {
+	if !s.sorted {
+		slices.Sort(s.samples)
+		s.sorted = true
+	}
+}
+
+// percentile picks the nearest rank p of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(float64(len(sorted))*p)) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
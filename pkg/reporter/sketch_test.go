@@ -0,0 +1,56 @@
+package reporter
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLatencySketch_ExactWhileSmall(t *testing.T) {
+	var s latencySketch
+	for _, ms := range []int{40, 10, 30, 20} {
+		s.add(time.Duration(ms) * time.Millisecond)
+	}
+	if s.quantile(0.5) != 20*time.Millisecond || s.quantile(1) != 40*time.Millisecond || s.mean() != 25*time.Millisecond {
+		t.Errorf("Expected exact quantiles, got median %s, max %s, mean %s", s.quantile(0.5), s.quantile(1), s.mean())
+	}
+}
+
+func TestLatencySketch_BoundedWhenLarge(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	var s latencySketch
+	var all []time.Duration
+	for i := 0; i < 200_000; i++ {
+		// a long tail from 1ms up to about 10s
+		d := time.Duration(rng.ExpFloat64()*float64(50*time.Millisecond)) + time.Millisecond
+		s.add(d)
+		all = append(all, d)
+	}
+	// a latency made negative by clock skew
+	s.add(-time.Millisecond)
+	all = append(all, -time.Millisecond)
+	slices.Sort(all)
+
+	if len(s.buckets) > 1000 {
+		t.Errorf("Expected memory to stay bounded, got %d buckets", len(s.buckets))
+	}
+	if s.count != len(all) || s.min != all[0] || s.max != all[len(all)-1] {
+		t.Errorf("Expected exact count, min and max, got %d %s %s", s.count, s.min, s.max)
+	}
+	for _, p := range []float64{0.5, 0.9, 0.95, 0.99, 0.999, 1} {
+		want, got := percentile(all, p), s.quantile(p)
+		if diff := float64(got-want) / float64(want); diff > 0.01 || diff < -0.01 {
+			t.Errorf("p%v: expected about %s, got %s", p*100, want, got)
+		}
+	}
+
+	counted := 0
+	s.each(func(_ time.Duration, count int) bool {
+		counted += count
+		return true
+	})
+	if counted != s.count {
+		t.Errorf("Expected buckets to hold every latency, got %d of %d", counted, s.count)
+	}
+}
@@ -3,6 +3,10 @@ package reporter
 import (
 	"fmt"
 	"io"
+	"sort"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
 )
 
 func PrintTextMetrics(w io.Writer, m Metrics) {
@@ -14,4 +18,153 @@ func PrintTextMetrics(w io.Writer, m Metrics) {
 	fmt.Fprintf(w, "%-30s: %s\n", "Median Response Time", m.MedianResponseTime)
 	fmt.Fprintf(w, "%-30s: %s\n", "95th Percentile Response Time", m.Percentile95Time)
 	fmt.Fprintf(w, "%-30s: %.2f\n", "Requests Per Second", m.RequestsPerSecond)
+	fmt.Fprintf(w, "%-30s: %d\n", "Skipped Requests", m.SkippedRequests)
+	fmt.Fprintf(w, "%-30s: %s\n", "Average Schedule Lag", m.AverageScheduleLag)
+	fmt.Fprintf(w, "%-30s: %s\n", "Maximum Schedule Lag", m.MaxScheduleLag)
+	fmt.Fprintf(w, "%-30s: %d\n", "Failed Requests", m.FailedRequests)
+	printOutcomes(w, m.Outcomes)
+	printStatusCodes(w, m.StatusCodes)
+	fmt.Fprintf(w, "%-30s: %s\n", "Average Sync Latency", m.AverageSyncLatency)
+	fmt.Fprintf(w, "%-30s: %s\n", "95th Percentile Sync Latency", m.Percentile95SyncLatency)
+	fmt.Fprintf(w, "%-30s: %s\n", "Maximum Sync Latency", m.MaxSyncLatency)
+	fmt.Fprintf(w, "%-30s: %d\n", "Invalid Signatures", m.InvalidSignatures)
+	fmt.Fprintf(w, "%-30s: %d\n", "Callback Attempts", m.CallbackAttempts)
+	fmt.Fprintf(w, "%-30s: %d\n", "Rejected Attempts", m.RejectedAttempts)
+	fmt.Fprintf(w, "%-30s: %d\n", "Retried Requests", m.RetriedRequests)
+	fmt.Fprintf(w, "%-30s: %s\n", "Average Retry Delay", m.AverageRetryDelay)
+	fmt.Fprintf(w, "%-30s: %s\n", "Maximum Retry Delay", m.MaxRetryDelay)
+	fmt.Fprintf(w, "%-30s: %d\n", "Redeliveries", m.Redeliveries)
+	fmt.Fprintf(w, "%-30s: %d\n", "Duplicate Deliveries", m.DuplicateDeliveries)
+	fmt.Fprintf(w, "%-30s: %.2f%%\n", "Duplicate Rate", m.DuplicateRate*100)
+	fmt.Fprintf(w, "%-30s: %s\n", "Minimum Redelivery Delay", m.MinRedeliveryDelay)
+	fmt.Fprintf(w, "%-30s: %s\n", "Average Redelivery Delay", m.AverageRedeliveryDelay)
+	fmt.Fprintf(w, "%-30s: %s\n", "Median Redelivery Delay", m.MedianRedeliveryDelay)
+	fmt.Fprintf(w, "%-30s: %s\n", "95th Pct Redelivery Delay", m.Percentile95RedeliveryDelay)
+	fmt.Fprintf(w, "%-30s: %s\n", "Maximum Redelivery Delay", m.MaxRedeliveryDelay)
+	fmt.Fprintf(w, "%-30s: %d\n", "Orphan Callbacks", m.Orphans.Total)
+	printOrphans(w, m.Orphans)
+	fmt.Fprintf(w, "%-30s: %d\n", "Failed Assertions", m.Assertions.FailedRequests)
+	printAssertions(w, m.Assertions)
+	fmt.Fprintf(w, "%-30s: %d\n", "Connection Drops", m.Disconnections.Total)
+	printDisconnections(w, m.Disconnections)
+	printThresholds(w, m.Thresholds)
+	printTimeSeries(w, m.TimeSeries)
+}
+
+func printThresholds(w io.Writer, results []ThresholdResult) {
+	if len(results) == 0 {
+		return
+	}
+	passed := 0
+	for _, result := range results {
+		if result.Passed {
+			passed++
+		}
+	}
+	fmt.Fprintf(w, "%-30s: %d of %d passed\n", "Thresholds", passed, len(results))
+	for _, result := range results {
+		fmt.Fprintf(w, "  %s\n", result.describe())
+	}
+}
+
+func printOutcomes(w io.Writer, outcomes map[tracker.Outcome]int) {
+	for _, outcome := range tracker.Outcomes {
+		if count := outcomes[outcome]; count > 0 {
+			fmt.Fprintf(w, "  %-28s: %d\n", outcome, count)
+		}
+	}
+}
+
+func printStatusCodes(w io.Writer, statusCodes map[int]int) {
+	codes := make([]int, 0, len(statusCodes))
+	for code := range statusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "  HTTP %d: %d\n", code, statusCodes[code])
+	}
+}
+
+func printAssertions(w io.Writer, m AssertionMetrics) {
+	rules := make([]string, 0, len(m.ByRule))
+	for rule := range m.ByRule {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		fmt.Fprintf(w, "  %s: %d\n", rule, m.ByRule[rule])
+		for _, example := range m.Examples[rule] {
+			fmt.Fprintf(w, "    - %s\n", truncate(example, 200))
+		}
+	}
+}
+
+func printOrphans(w io.Writer, m OrphanMetrics) {
+	reasons := make([]string, 0, len(m.ByReason))
+	for reason := range m.ByReason {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "  %-28s: %d\n", reason, m.ByReason[reason])
+	}
+
+	for i, orphan := range m.Samples {
+		fmt.Fprintf(w, "  Sample %d (%s at %s)\n", i+1, orphan.Reason, orphan.Time.Format("15:04:05.000"))
+		names := make([]string, 0, len(orphan.Headers))
+		for name := range orphan.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "    %s: %v\n", name, orphan.Headers[name])
+		}
+		fmt.Fprintf(w, "    %s\n", truncate(orphan.Body, 200))
+	}
+}
+
+func printDisconnections(w io.Writer, m DisconnectionMetrics) {
+	if m.Total == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  %-28s: %d\n", "Never Reconnected", m.Unrecovered)
+	fmt.Fprintf(w, "  %-28s: %s\n", "Total Downtime", m.TotalDowntime)
+	fmt.Fprintf(w, "  %-28s: %s\n", "Maximum Downtime", m.MaxDowntime)
+	for i, d := range m.Samples {
+		fmt.Fprintf(w, "  Drop %d at %s, down %s: %s\n", i+1, d.Time.Format("15:04:05.000"), d.Downtime, truncate(d.Reason, 200))
+	}
+}
+
+func printTimeSeries(w io.Writer, ts TimeSeries) {
+	if len(ts.BySendTime) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%-30s: %s buckets from %s\n", "Time Series", ts.Interval, ts.BySendTime[0].Start.Format("15:04:05"))
+	fmt.Fprintln(w, "  By Send Time")
+	printBuckets(w, ts.BySendTime)
+	fmt.Fprintln(w, "  By Arrival Time")
+	printBuckets(w, ts.ByArrivalTime)
+}
+
+func printBuckets(w io.Writer, buckets []Bucket) {
+	fmt.Fprintf(w, "    %-8s %7s %9s %7s %10s %10s %10s %10s %10s\n", "Offset", "Sends", "Callbacks", "Errors", "Avg", "P50", "P95", "P99", "Max")
+	for _, b := range buckets {
+		fmt.Fprintf(w, "    %-8s %7d %9d %7d %10s %10s %10s %10s %10s\n",
+			"+"+b.Start.Sub(buckets[0].Start).String(), b.Sends, b.Callbacks, b.Errors,
+			roundLatency(b.AverageLatency), roundLatency(b.MedianLatency), roundLatency(b.P95Latency),
+			roundLatency(b.P99Latency), roundLatency(b.MaxLatency))
+	}
+}
+
+// roundLatency keeps bucket latencies short enough for their column.
+func roundLatency(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
 }
@@ -0,0 +1,130 @@
+package reporter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// ThresholdResult is how the run fared against one threshold.
+type ThresholdResult struct {
+	// Name describes the bounds, eg. p95ResponseTime <= 2000
+	Name   string
+	Metric string
+	Value  float64
+	Passed bool
+}
+
+// thresholdMetrics are the metrics thresholds can bound.
+var thresholdMetrics = map[string]func(Metrics) float64{
+	"averageResponseTime": func(m Metrics) float64 { return milliseconds(m.AverageResponseTime) },
+	"medianResponseTime":  func(m Metrics) float64 { return milliseconds(m.MedianResponseTime) },
+	"p95ResponseTime":     func(m Metrics) float64 { return milliseconds(m.Percentile95Time) },
+	"maxResponseTime":     func(m Metrics) float64 { return milliseconds(m.MaxResponseTime) },
+	"averageSyncLatency":  func(m Metrics) float64 { return milliseconds(m.AverageSyncLatency) },
+	"p95SyncLatency":      func(m Metrics) float64 { return milliseconds(m.Percentile95SyncLatency) },
+	"maxSyncLatency":      func(m Metrics) float64 { return milliseconds(m.MaxSyncLatency) },
+	"averageScheduleLag":  func(m Metrics) float64 { return milliseconds(m.AverageScheduleLag) },
+	"maxScheduleLag":      func(m Metrics) float64 { return milliseconds(m.MaxScheduleLag) },
+	"requestsPerSecond":   func(m Metrics) float64 { return m.RequestsPerSecond },
+	"failedRequests":      func(m Metrics) float64 { return float64(m.FailedRequests) },
+	"failureRate": func(m Metrics) float64 {
+		if m.TotalRequests == 0 {
+			return 0
+		}
+		return float64(m.FailedRequests) / float64(m.TotalRequests)
+	},
+	// nonOkRequests counts every request that didn't end ok, timed out
+	// ones included
+	"nonOkRequests": func(m Metrics) float64 {
+		return float64(m.TotalRequests - m.Outcomes[tracker.OutcomeOK])
+	},
+	"skippedRequests":   func(m Metrics) float64 { return float64(m.SkippedRequests) },
+	"timedOutRequests":  func(m Metrics) float64 { return float64(m.Outcomes[tracker.OutcomeTimedOut]) },
+	"invalidSignatures": func(m Metrics) float64 { return float64(m.InvalidSignatures) },
+	"failedAssertions":  func(m Metrics) float64 { return float64(m.Assertions.FailedRequests) },
+	"retriedRequests":   func(m Metrics) float64 { return float64(m.RetriedRequests) },
+	"duplicateRate":     func(m Metrics) float64 { return m.DuplicateRate },
+	"orphanCallbacks":   func(m Metrics) float64 { return float64(m.Orphans.Total) },
+	"connectionDrops":   func(m Metrics) float64 { return float64(m.Disconnections.Total) },
+}
+
+// DefaultThresholds are what the junit output checks when the config has
+// no thresholds: every request must succeed.
+var DefaultThresholds = []types.Threshold{{Metric: "nonOkRequests", Max: new(float64)}}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ThresholdMetrics lists the metrics thresholds can bound.
+func ThresholdMetrics() []string {
+	names := make([]string, 0, len(thresholdMetrics))
+	for name := range thresholdMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateThresholds checks every threshold bounds a known metric.
+func ValidateThresholds(thresholds []types.Threshold) error {
+	for _, threshold := range thresholds {
+		if _, found := thresholdMetrics[threshold.Metric]; !found {
+			return errors.New("Unknown threshold metric: " + threshold.Metric + ", expected one of " + strings.Join(ThresholdMetrics(), ", "))
+		}
+		if threshold.Min == nil && threshold.Max == nil {
+			return errors.New("Threshold needs a min or a max: " + threshold.Metric)
+		}
+	}
+	return nil
+}
+
+// EvaluateThresholds checks m against every threshold, unknown metrics
+// fail.
+func EvaluateThresholds(m Metrics, thresholds []types.Threshold) []ThresholdResult {
+	results := make([]ThresholdResult, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		result := ThresholdResult{Name: thresholdName(threshold), Metric: threshold.Metric}
+		if value, found := thresholdMetrics[threshold.Metric]; found {
+			result.Value = value(m)
+			result.Passed = (threshold.Min == nil || result.Value >= *threshold.Min) &&
+				(threshold.Max == nil || result.Value <= *threshold.Max)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func thresholdName(threshold types.Threshold) string {
+	var bounds []string
+	if threshold.Min != nil {
+		bounds = append(bounds, threshold.Metric+" >= "+formatValue(*threshold.Min))
+	}
+	if threshold.Max != nil {
+		bounds = append(bounds, threshold.Metric+" <= "+formatValue(*threshold.Max))
+	}
+	if len(bounds) == 0 {
+		return threshold.Metric
+	}
+	return strings.Join(bounds, " and ")
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// describe explains how the value compares to the bounds.
+func (r ThresholdResult) describe() string {
+	verdict := "passed"
+	if !r.Passed {
+		verdict = "failed"
+	}
+	return fmt.Sprintf("%s %s, was %s", r.Name, verdict, strconv.FormatFloat(r.Value, 'f', 2, 64))
+}
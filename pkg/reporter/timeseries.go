@@ -0,0 +1,162 @@
+package reporter
+
+import (
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+// DefaultBucketInterval is how much of the run every time series bucket
+// covers unless configured otherwise.
+var DefaultBucketInterval = time.Second
+
+// Bucket summarises one interval of the run.
+type Bucket struct {
+	Start     time.Time
+	Sends     int
+	Callbacks int
+	Errors    int
+	// Latencies are from sending to the first accepted callback
+	AverageLatency time.Duration
+	MedianLatency  time.Duration
+	P95Latency     time.Duration
+	P99Latency     time.Duration
+	MaxLatency     time.Duration
+}
+
+// TimeSeries splits the run into intervals, so latency creeping up as the
+// provider falls behind shows instead of being averaged away. Both series
+// cover the same intervals.
+type TimeSeries struct {
+	Interval time.Duration
+	// BySendTime groups requests by when they were sent. Callbacks and
+	// errors are those of the requests sent in the interval, even when
+	// they came later.
+	BySendTime []Bucket
+	// ByArrivalTime counts everything when it happened: sends when they
+	// were sent, callbacks and their latency when they arrived and errors
+	// when they were known. Time outs are never known, so they only count
+	// by send time.
+	ByArrivalTime []Bucket
+}
+
+// bucketSamples gathers the requests of a bucket until they are summarised.
+type bucketSamples struct {
+	sends, callbacks, errors int
+	latencies                latencySketch
+}
+
+// timeSeriesBuilder places records in buckets, which are keyed by their
+// index since the zero time so records can come in any order.
+type timeSeriesBuilder struct {
+	interval  time.Duration
+	bySend    map[int64]*bucketSamples
+	byArrival map[int64]*bucketSamples
+}
+
+func newTimeSeriesBuilder(interval time.Duration) *timeSeriesBuilder {
+	if interval <= 0 {
+		interval = DefaultBucketInterval
+	}
+	return &timeSeriesBuilder{
+		interval:  interval,
+		bySend:    make(map[int64]*bucketSamples),
+		byArrival: make(map[int64]*bucketSamples),
+	}
+}
+
+func (b *timeSeriesBuilder) bucket(buckets map[int64]*bucketSamples, t time.Time) *bucketSamples {
+	i := t.UnixNano() / int64(b.interval)
+	s, found := buckets[i]
+	if !found {
+		s = &bucketSamples{}
+		buckets[i] = s
+	}
+	return s
+}
+
+// add places a record in both series. Skipped requests were never sent and
+// are left out.
+func (b *timeSeriesBuilder) add(pair tracker.RequestTrackerPair) {
+	if pair.Skipped || pair.StartTime.IsZero() {
+		return
+	}
+	outcome := pair.Outcome()
+	failed := outcome != tracker.OutcomeOK
+
+	sent := b.bucket(b.bySend, pair.StartTime)
+	sent.sends++
+	if failed {
+		sent.errors++
+	}
+	if !pair.EndTime.IsZero() {
+		sent.callbacks++
+		sent.latencies.add(pair.CallbackLatency())
+	}
+
+	b.bucket(b.byArrival, pair.StartTime).sends++
+	if !pair.EndTime.IsZero() {
+		arrived := b.bucket(b.byArrival, pair.EndTime)
+		arrived.callbacks++
+		arrived.latencies.add(pair.CallbackLatency())
+	}
+	switch {
+	case outcome == tracker.OutcomeTriggerFailed:
+		failedAt := pair.SendEndTime
+		if failedAt.IsZero() {
+			failedAt = pair.StartTime
+		}
+		b.bucket(b.byArrival, failedAt).errors++
+	case failed && !pair.EndTime.IsZero():
+		// wrongly signed or broke a rule, known once the callback came
+		b.bucket(b.byArrival, pair.EndTime).errors++
+	}
+}
+
+// build summarises the buckets, filling the intervals nothing happened in
+// so both series are continuous and line up.
+func (b *timeSeriesBuilder) build() TimeSeries {
+	series := TimeSeries{Interval: b.interval}
+	if len(b.bySend) == 0 {
+		return series
+	}
+
+	first, last := int64(0), int64(0)
+	started := false
+	for _, buckets := range []map[int64]*bucketSamples{b.bySend, b.byArrival} {
+		for i := range buckets {
+			if !started || i < first {
+				first = i
+			}
+			if !started || i > last {
+				last = i
+			}
+			started = true
+		}
+	}
+
+	for i := first; i <= last; i++ {
+		start := time.Unix(0, i*int64(b.interval))
+		series.BySendTime = append(series.BySendTime, summarise(start, b.bySend[i]))
+		series.ByArrivalTime = append(series.ByArrivalTime, summarise(start, b.byArrival[i]))
+	}
+	return series
+}
+
+func summarise(start time.Time, s *bucketSamples) Bucket {
+	bucket := Bucket{Start: start}
+	if s == nil {
+		return bucket
+	}
+	bucket.Sends, bucket.Callbacks, bucket.Errors = s.sends, s.callbacks, s.errors
+	if s.latencies.count == 0 {
+		return bucket
+	}
+
+	bucket.AverageLatency = s.latencies.mean()
+	bucket.MedianLatency = s.latencies.quantile(0.50)
+	bucket.P95Latency = s.latencies.quantile(0.95)
+	bucket.P99Latency = s.latencies.quantile(0.99)
+	bucket.MaxLatency = s.latencies.max
+	return bucket
+}
@@ -0,0 +1,67 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+func TestTimeSeries(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	pairs := []tracker.RequestTrackerPair{
+		// sent in the first second, called back in the third
+		{StartTime: at(100 * time.Millisecond), SendEndTime: at(150 * time.Millisecond), EndTime: at(2500 * time.Millisecond)},
+		{StartTime: at(200 * time.Millisecond), SendEndTime: at(250 * time.Millisecond), EndTime: at(700 * time.Millisecond)},
+		// failed to send in the second second
+		{StartTime: at(1100 * time.Millisecond), SendEndTime: at(1200 * time.Millisecond), SendFailed: true},
+		// never called back
+		{StartTime: at(1300 * time.Millisecond), SendEndTime: at(1350 * time.Millisecond)},
+		// never sent
+		{ScheduledTime: at(1400 * time.Millisecond), Skipped: true},
+	}
+	m, err := CalculateStoreMetrics(pairRecords(pairs), 3*time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := m.TimeSeries
+	if ts.Interval != time.Second || len(ts.BySendTime) != 3 || len(ts.ByArrivalTime) != 3 {
+		t.Fatalf("Expected 3 one second buckets, got %+v", ts)
+	}
+	if !ts.BySendTime[0].Start.Equal(start) {
+		t.Errorf("Expected buckets to start on the second, got %s", ts.BySendTime[0].Start)
+	}
+
+	bySend := ts.BySendTime
+	if b := bySend[0]; b.Sends != 2 || b.Callbacks != 2 || b.Errors != 0 || b.MaxLatency != 2400*time.Millisecond || b.MedianLatency != 500*time.Millisecond {
+		t.Errorf("Unexpected first bucket by send time %+v", b)
+	}
+	if b := bySend[1]; b.Sends != 2 || b.Callbacks != 0 || b.Errors != 2 {
+		t.Errorf("Expected the failed and timed out requests in the second bucket, got %+v", b)
+	}
+	if b := bySend[2]; b.Sends != 0 || b.Callbacks != 0 {
+		t.Errorf("Expected nothing sent in the third bucket, got %+v", b)
+	}
+
+	byArrival := ts.ByArrivalTime
+	if b := byArrival[0]; b.Sends != 2 || b.Callbacks != 1 || b.MaxLatency != 500*time.Millisecond {
+		t.Errorf("Unexpected first bucket by arrival time %+v", b)
+	}
+	// the time out is never known, only the failed send counts
+	if b := byArrival[1]; b.Sends != 2 || b.Callbacks != 0 || b.Errors != 1 {
+		t.Errorf("Unexpected second bucket by arrival time %+v", b)
+	}
+	if b := byArrival[2]; b.Callbacks != 1 || b.P99Latency != 2400*time.Millisecond {
+		t.Errorf("Expected the late callback in the third bucket, got %+v", b)
+	}
+
+	var report bytes.Buffer
+	PrintTextMetrics(&report, m)
+	if !strings.Contains(report.String(), "By Arrival Time") || !strings.Contains(report.String(), "+2s") {
+		t.Errorf("Expected the time series in the report, got\n%s", report.String())
+	}
+}
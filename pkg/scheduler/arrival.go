@@ -0,0 +1,178 @@
+package scheduler
+
+import (
+	"bufio"
+	"errors"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	ArrivalConstant = "constant"
+	ArrivalPoisson  = "poisson"
+	ArrivalUniform  = "uniform"
+	ArrivalReplay   = "replay"
+)
+
+// Arrival yields the gap to wait between two consecutive requests.
+type Arrival interface {
+	Next() time.Duration
+}
+
+// NewArrival builds the arrival process described by cfg around meanGap,
+// the average time between two requests.
+func NewArrival(cfg types.ArrivalConfig, meanGap time.Duration) (Arrival, error) {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+
+	switch cfg.Type {
+	case "", ArrivalConstant:
+		return constantArrival{gap: meanGap}, nil
+	case ArrivalPoisson:
+		return &poissonArrival{mean: meanGap, rnd: rnd}, nil
+	case ArrivalUniform:
+		jitter := cfg.Jitter
+		if jitter == 0 {
+			jitter = 1
+		}
+		if jitter < 0 || jitter > 1 {
+			return nil, errors.New("Arrival jitter must be between 0 and 1")
+		}
+		return &uniformArrival{mean: meanGap, jitter: jitter, rnd: rnd}, nil
+	case ArrivalReplay:
+		gaps, err := readGaps(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		return newReplayArrival(gaps, meanGap), nil
+	default:
+		return nil, errors.New("Unknown arrival type: " + cfg.Type)
+	}
+}
+
+// MeanGap spreads iterations evenly over duration.
+func MeanGap(iterations int, duration time.Duration) time.Duration {
+	if iterations <= 0 {
+		return 0
+	}
+	return duration / time.Duration(iterations)
+}
+
+type constantArrival struct {
+	gap time.Duration
+}
+
+func (a constantArrival) Next() time.Duration {
+	return a.gap
+}
+
+// poissonArrival draws exponentially distributed gaps, which makes the
+// number of arrivals per interval follow a poisson distribution.
+type poissonArrival struct {
+	mean time.Duration
+	rnd  *rand.Rand
+}
+
+func (a *poissonArrival) Next() time.Duration {
+	return time.Duration(a.rnd.ExpFloat64() * float64(a.mean))
+}
+
+// uniformArrival draws gaps uniformly from mean ± jitter*mean.
+type uniformArrival struct {
+	mean   time.Duration
+	jitter float64
+	rnd    *rand.Rand
+}
+
+func (a *uniformArrival) Next() time.Duration {
+	spread := a.jitter * (2*a.rnd.Float64() - 1)
+	return time.Duration(float64(a.mean) * (1 + spread))
+}
+
+// replayArrival cycles through recorded gaps, scaled so that their mean
+// matches the configured rate.
+type replayArrival struct {
+	gaps []time.Duration
+	pos  int
+}
+
+func newReplayArrival(gaps []time.Duration, meanGap time.Duration) *replayArrival {
+	var total time.Duration
+	for _, g := range gaps {
+		total += g
+	}
+	scale := 1.0
+	if total > 0 {
+		recordedMean := float64(total) / float64(len(gaps))
+		scale = float64(meanGap) / recordedMean
+	}
+
+	scaled := make([]time.Duration, len(gaps))
+	for i, g := range gaps {
+		scaled[i] = time.Duration(float64(g) * scale)
+	}
+	return &replayArrival{gaps: scaled}
+}
+
+func (a *replayArrival) Next() time.Duration {
+	gap := a.gaps[a.pos]
+	a.pos = (a.pos + 1) % len(a.gaps)
+	return gap
+}
+
+// readGaps reads one inter-arrival time per line. Lines may either be Go
+// durations (150ms, 1.5s) or plain numbers in milliseconds. Blank lines and
+// lines starting with # are ignored.
+func readGaps(path string) ([]time.Duration, error) {
+	if path == "" {
+		return nil, errors.New("Arrival file is required for replay")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("Could not open arrival file: " + path)
+	}
+	defer f.Close()
+
+	var gaps []time.Duration
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		gap, err := parseGap(line)
+		if err != nil {
+			return nil, errors.New("Invalid inter-arrival time: " + line)
+		}
+		gaps = append(gaps, gap)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(gaps) == 0 {
+		return nil, errors.New("Arrival file has no inter-arrival times: " + path)
+	}
+	return gaps, nil
+}
+
+func parseGap(s string) (time.Duration, error) {
+	if ms, err := strconv.ParseFloat(s, 64); err == nil {
+		if ms < 0 {
+			return 0, errors.New("negative gap")
+		}
+		return time.Duration(ms * float64(time.Millisecond)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, errors.New("invalid gap")
+	}
+	return d, nil
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func meanOf(a Arrival, n int) time.Duration {
+	var total time.Duration
+	for i := 0; i < n; i++ {
+		total += a.Next()
+	}
+	return total / time.Duration(n)
+}
+
+func TestNewArrival_KeepsMeanRate(t *testing.T) {
+	meanGap := 10 * time.Millisecond
+
+	gapsFile := filepath.Join(t.TempDir(), "gaps.txt")
+	os.WriteFile(gapsFile, []byte("# recorded gaps\n1\n2ms\n\n9\n"), 0644)
+
+	cases := []types.ArrivalConfig{
+		{Type: ArrivalConstant},
+		{Type: ArrivalPoisson, Seed: 42},
+		{Type: ArrivalUniform, Jitter: 0.5, Seed: 42},
+		{Type: ArrivalReplay, File: gapsFile},
+	}
+
+	for _, cfg := range cases {
+		a, err := NewArrival(cfg, meanGap)
+		if err != nil {
+			t.Fatalf("%s: NewArrival failed: %v", cfg.Type, err)
+		}
+
+		got := meanOf(a, 20000)
+		if diff := got - meanGap; diff > meanGap/20 || diff < -meanGap/20 {
+			t.Errorf("%s: expected mean gap around %s, got %s", cfg.Type, meanGap, got)
+		}
+	}
+}
+
+func TestNewArrival_RejectsUnknownType(t *testing.T) {
+	if _, err := NewArrival(types.ArrivalConfig{Type: "bursty"}, time.Second); err == nil {
+		t.Errorf("Expected an error for unknown arrival type")
+	}
+}
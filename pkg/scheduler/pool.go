@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+)
+
+const (
+	// PolicyBlock makes Submit wait for a free worker, the delay shows up
+	// as schedule lag in the report.
+	PolicyBlock = "block"
+	// PolicyDrop makes Submit give up straight away when every worker is
+	// busy, the job is then counted as skipped.
+	PolicyDrop = "drop"
+)
+
+// Pool runs submitted jobs with at most size of them in flight at once.
+// A size of 0 keeps the old behaviour of one goroutine per job.
+type Pool struct {
+	size   int
+	policy string
+	// slots holds a token per job in flight, queued or running, so Submit
+	// only waits or drops when size jobs already are
+	slots chan struct{}
+	jobs  chan func()
+	wg    sync.WaitGroup
+}
+
+func ValidatePolicy(policy string) error {
+	switch policy {
+	case "", PolicyBlock, PolicyDrop:
+		return nil
+	}
+	return errors.New("Unknown in-flight policy: " + policy)
+}
+
+func NewPool(size int, policy string) *Pool {
+	if policy == "" {
+		policy = PolicyBlock
+	}
+	p := &Pool{
+		size:   size,
+		policy: policy,
+		slots:  make(chan struct{}, max(size, 0)),
+		jobs:   make(chan func(), max(size, 0)),
+	}
+
+	for i := 0; i < size; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				job()
+				<-p.slots
+			}
+		}()
+	}
+	return p
+}
+
+// Submit hands job to the pool and reports whether it was accepted. Only
+// the drop policy ever rejects a job.
+func (p *Pool) Submit(job func()) bool {
+	if p.size <= 0 {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			job()
+		}()
+		return true
+	}
+
+	if p.policy == PolicyDrop {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			return false
+		}
+	} else {
+		p.slots <- struct{}{}
+	}
+	// holding a slot, the buffer of jobs always has room
+	p.jobs <- job
+	return true
+}
+
+// Close stops accepting jobs, already accepted jobs still run to completion.
+func (p *Pool) Close() {
+	close(p.jobs)
+}
+
+// Wait blocks until every accepted job has finished. Close must be called
+// first for a bounded pool.
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
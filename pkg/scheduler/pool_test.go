@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_BlockNeverExceedsLimit(t *testing.T) {
+	pool := NewPool(3, PolicyBlock)
+
+	var inFlight, peak, ran atomic.Int32
+	for i := 0; i < 30; i++ {
+		accepted := pool.Submit(func() {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			inFlight.Add(-1)
+			ran.Add(1)
+		})
+		if !accepted {
+			t.Fatalf("Expected the block policy to accept every job")
+		}
+	}
+	pool.Close()
+	pool.Wait()
+
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 jobs in flight, got %d", peak.Load())
+	}
+	if ran.Load() != 30 {
+		t.Errorf("Expected every job to run, got %d", ran.Load())
+	}
+}
+
+func TestPool_DropWhenSaturated(t *testing.T) {
+	pool := NewPool(1, PolicyDrop)
+	defer pool.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	// an idle pool accepts jobs before its workers are waiting for them
+	if !pool.Submit(func() { close(started); <-release }) {
+		t.Fatalf("Expected the first job on an idle pool to be accepted")
+	}
+	<-started
+
+	if pool.Submit(func() {}) {
+		t.Errorf("Expected a job to be dropped while the only worker is busy")
+	}
+	close(release)
+}
+
+func TestPool_DropAcceptsUpToLimit(t *testing.T) {
+	for run := 0; run < 100; run++ {
+		pool := NewPool(4, PolicyDrop)
+		release := make(chan struct{})
+		for i := 0; i < 4; i++ {
+			if !pool.Submit(func() { <-release }) {
+				t.Fatalf("Run %d: expected job %d of 4 to be accepted", run, i+1)
+			}
+		}
+		if pool.Submit(func() {}) {
+			t.Fatalf("Run %d: expected a fifth job to be dropped", run)
+		}
+		close(release)
+		pool.Close()
+		pool.Wait()
+	}
+}
+
+func TestPool_BlockDelaysJobs(t *testing.T) {
+	pool := NewPool(1, PolicyBlock)
+	busy := 50 * time.Millisecond
+
+	var lock sync.Mutex
+	lags := []time.Duration{}
+	for i := 0; i < 2; i++ {
+		// jobs record how late they started, as requests do
+		scheduled := time.Now()
+		pool.Submit(func() {
+			lock.Lock()
+			lags = append(lags, time.Since(scheduled))
+			lock.Unlock()
+			time.Sleep(busy)
+		})
+	}
+	pool.Close()
+	pool.Wait()
+
+	if lags[1] < busy*4/5 {
+		t.Errorf("Expected the second job to wait for the first, lag was %s", lags[1])
+	}
+}
+
+func TestPool_Unbounded(t *testing.T) {
+	pool := NewPool(0, PolicyDrop)
+	release := make(chan struct{})
+	var ran atomic.Int32
+	for i := 0; i < 10; i++ {
+		if !pool.Submit(func() { <-release; ran.Add(1) }) {
+			t.Fatalf("Expected an unbounded pool to accept every job")
+		}
+	}
+	close(release)
+	pool.Close()
+	pool.Wait()
+	if ran.Load() != 10 {
+		t.Errorf("Expected every job to run, got %d", ran.Load())
+	}
+}
@@ -0,0 +1,45 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"hash"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// hmacScheme signs the raw body and puts the encoded digest, optionally
+// prefixed (sha256=...), in a single header.
+type hmacScheme struct {
+	key     []byte
+	newHash func() hash.Hash
+	header  string
+	prefix  string
+	encode  func([]byte) string
+	decode  func(string) ([]byte, error)
+}
+
+func (s *hmacScheme) Sign(h http.Header, body []byte, now time.Time) error {
+	h.Set(s.header, s.prefix+s.encode(computeHMAC(s.newHash, s.key, body)))
+	return nil
+}
+
+func (s *hmacScheme) Verify(h http.Header, body []byte, now time.Time) error {
+	value := h.Get(s.header)
+	if value == "" {
+		return types.MissingSignatureErr
+	}
+	if !strings.HasPrefix(value, s.prefix) {
+		return types.InvalidSignatureErr
+	}
+	got, err := s.decode(strings.TrimPrefix(value, s.prefix))
+	if err != nil {
+		return types.InvalidSignatureErr
+	}
+	if !hmac.Equal(got, computeHMAC(s.newHash, s.key, body)) {
+		return types.InvalidSignatureErr
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package signature signs and verifies webhook payloads using the schemes
+// commonly used by webhook providers.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	SchemeHMAC             = "hmac"
+	SchemeStripe           = "stripe"
+	SchemeStandardWebhooks = "standard-webhooks"
+)
+
+const DEFAULT_TOLERANCE = 5 * time.Minute
+
+// Scheme signs outgoing and verifies incoming webhook payloads.
+type Scheme interface {
+	// Sign sets the signature headers for body on h.
+	Sign(h http.Header, body []byte, now time.Time) error
+	// Verify checks the signature headers found on h against body.
+	Verify(h http.Header, body []byte, now time.Time) error
+}
+
+// New builds the scheme described by cfg. A nil scheme is returned when no
+// scheme is configured.
+func New(cfg types.SignatureConfig) (Scheme, error) {
+	if cfg.Scheme == "" {
+		return nil, nil
+	}
+
+	secret := cfg.Secret
+	if cfg.SecretEnv != "" {
+		secret = os.Getenv(cfg.SecretEnv)
+	}
+	if secret == "" {
+		return nil, errors.New("Signature secret is missing")
+	}
+
+	tolerance := DEFAULT_TOLERANCE
+	if cfg.ToleranceSeconds != 0 {
+		tolerance = time.Duration(cfg.ToleranceSeconds) * time.Second
+	}
+
+	switch cfg.Scheme {
+	case SchemeHMAC:
+		newHash, err := hashFor(cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		encode, decode, err := encodingFor(cfg.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		header := cfg.Header
+		if header == "" {
+			header = "X-Signature"
+		}
+		return &hmacScheme{
+			key:     []byte(secret),
+			newHash: newHash,
+			header:  header,
+			prefix:  cfg.Prefix,
+			encode:  encode,
+			decode:  decode,
+		}, nil
+	case SchemeStripe:
+		header := cfg.Header
+		if header == "" {
+			header = "Stripe-Signature"
+		}
+		return &stripeScheme{key: []byte(secret), header: header, tolerance: tolerance}, nil
+	case SchemeStandardWebhooks:
+		return newStandardWebhooks(secret, tolerance)
+	default:
+		return nil, errors.New("Unknown signature scheme: " + cfg.Scheme)
+	}
+}
+
+func hashFor(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "", "sha256":
+		return sha256.New, nil
+	case "sha1":
+		return sha1.New, nil
+	default:
+		return nil, errors.New("Unknown signature algorithm: " + algorithm)
+	}
+}
+
+func encodingFor(encoding string) (func([]byte) string, func(string) ([]byte, error), error) {
+	switch encoding {
+	case "", "hex":
+		return hex.EncodeToString, hex.DecodeString, nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString, base64.StdEncoding.DecodeString, nil
+	default:
+		return nil, nil, errors.New("Unknown signature encoding: " + encoding)
+	}
+}
+
+func computeHMAC(newHash func() hash.Hash, key []byte, parts ...[]byte) []byte {
+	mac := hmac.New(newHash, key)
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return mac.Sum(nil)
+}
+
+func withinTolerance(ts time.Time, now time.Time, tolerance time.Duration) bool {
+	diff := now.Sub(ts)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tolerance
+}
@@ -0,0 +1,80 @@
+package signature
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func TestScheme_SignThenVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id": "evt_1", "amount": 42}`)
+
+	cases := []types.SignatureConfig{
+		{Scheme: SchemeHMAC, Secret: "s3cret"},
+		{Scheme: SchemeHMAC, Secret: "s3cret", Algorithm: "sha1", Encoding: "base64", Header: "X-Hub-Signature", Prefix: "sha1="},
+		{Scheme: SchemeStripe, Secret: "whsec_test"},
+		{Scheme: SchemeStandardWebhooks, Secret: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"},
+	}
+
+	for _, cfg := range cases {
+		scheme, err := New(cfg)
+		if err != nil {
+			t.Fatalf("%s: New failed: %v", cfg.Scheme, err)
+		}
+
+		h := http.Header{}
+		scheme.Sign(h, body, now)
+
+		if err := scheme.Verify(h, body, now.Add(time.Minute)); err != nil {
+			t.Errorf("%s: expected valid signature, got %v", cfg.Scheme, err)
+		}
+		if err := scheme.Verify(h, []byte(`{"id": "evt_1", "amount": 43}`), now); !errors.Is(err, types.InvalidSignatureErr) {
+			t.Errorf("%s: expected tampered body to be rejected, got %v", cfg.Scheme, err)
+		}
+		if err := scheme.Verify(http.Header{}, body, now); !errors.Is(err, types.MissingSignatureErr) {
+			t.Errorf("%s: expected missing signature, got %v", cfg.Scheme, err)
+		}
+	}
+}
+
+func TestScheme_RejectsStaleTimestamps(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{}`)
+
+	for _, name := range []string{SchemeStripe, SchemeStandardWebhooks} {
+		scheme, _ := New(types.SignatureConfig{
+			Scheme:           name,
+			Secret:           "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw",
+			ToleranceSeconds: 60,
+		})
+
+		h := http.Header{}
+		scheme.Sign(h, body, now)
+
+		if err := scheme.Verify(h, body, now.Add(2*time.Minute)); !errors.Is(err, types.SignatureTimestampErr) {
+			t.Errorf("%s: expected timestamp error, got %v", name, err)
+		}
+	}
+}
+
+func TestStandardWebhooks_KnownSignature(t *testing.T) {
+	// test vector from the standard webhooks specification
+	scheme, _ := New(types.SignatureConfig{
+		Scheme:           SchemeStandardWebhooks,
+		Secret:           "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw",
+		ToleranceSeconds: 1,
+	})
+
+	h := http.Header{}
+	h.Set("webhook-id", "msg_p5jXN8AQM9LWM0D4loKWxJek")
+	h.Set("webhook-timestamp", "1614265330")
+	h.Set("webhook-signature", "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=")
+
+	if err := scheme.Verify(h, []byte(`{"test": 2432232314}`), time.Unix(1614265330, 0)); err != nil {
+		t.Errorf("Expected known signature to verify, got %v", err)
+	}
+}
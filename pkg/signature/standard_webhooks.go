@@ -0,0 +1,80 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	webhookIDHeader        = "webhook-id"
+	webhookTimestampHeader = "webhook-timestamp"
+	webhookSignatureHeader = "webhook-signature"
+)
+
+// standardWebhooks implements https://www.standardwebhooks.com, the signed
+// content being "<webhook-id>.<webhook-timestamp>.<body>".
+type standardWebhooks struct {
+	key       []byte
+	tolerance time.Duration
+}
+
+func newStandardWebhooks(secret string, tolerance time.Duration) (*standardWebhooks, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+	if err != nil {
+		return nil, errors.New("Standard webhooks secret must be base64 encoded")
+	}
+	return &standardWebhooks{key: key, tolerance: tolerance}, nil
+}
+
+func (s *standardWebhooks) sign(id, ts string, body []byte) string {
+	sig := computeHMAC(sha256.New, s.key, []byte(id), []byte("."), []byte(ts), []byte("."), body)
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func (s *standardWebhooks) Sign(h http.Header, body []byte, now time.Time) error {
+	id := h.Get(webhookIDHeader)
+	if id == "" {
+		id = "msg_" + uuid.New().String()
+		h.Set(webhookIDHeader, id)
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	h.Set(webhookTimestampHeader, ts)
+	h.Set(webhookSignatureHeader, "v1,"+s.sign(id, ts, body))
+	return nil
+}
+
+func (s *standardWebhooks) Verify(h http.Header, body []byte, now time.Time) error {
+	id := h.Get(webhookIDHeader)
+	ts := h.Get(webhookTimestampHeader)
+	value := h.Get(webhookSignatureHeader)
+	if id == "" || ts == "" || value == "" {
+		return types.MissingSignatureErr
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return types.InvalidSignatureErr
+	}
+	if !withinTolerance(time.Unix(unix, 0), now, s.tolerance) {
+		return types.SignatureTimestampErr
+	}
+
+	expected := s.sign(id, ts, body)
+	// several space separated signatures may be sent while keys are rotated
+	for _, versioned := range strings.Fields(value) {
+		version, sig, found := strings.Cut(versioned, ",")
+		if found && version == "v1" && hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return types.InvalidSignatureErr
+}
@@ -0,0 +1,69 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// stripeScheme implements the t=<unix>,v1=<hex> header used by Stripe, the
+// signed payload being "<t>.<body>".
+type stripeScheme struct {
+	key       []byte
+	header    string
+	tolerance time.Duration
+}
+
+func (s *stripeScheme) Sign(h http.Header, body []byte, now time.Time) error {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := computeHMAC(sha256.New, s.key, []byte(ts), []byte("."), body)
+	h.Set(s.header, "t="+ts+",v1="+hex.EncodeToString(sig))
+	return nil
+}
+
+func (s *stripeScheme) Verify(h http.Header, body []byte, now time.Time) error {
+	value := h.Get(s.header)
+	if value == "" {
+		return types.MissingSignatureErr
+	}
+
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(value, ",") {
+		k, v, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return types.InvalidSignatureErr
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return types.InvalidSignatureErr
+	}
+	if !withinTolerance(time.Unix(unix, 0), now, s.tolerance) {
+		return types.SignatureTimestampErr
+	}
+
+	expected := computeHMAC(sha256.New, s.key, []byte(ts), []byte("."), body)
+	for _, sig := range signatures {
+		if got, err := hex.DecodeString(sig); err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return types.InvalidSignatureErr
+}
@@ -0,0 +1,121 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sseConn parses a text/event-stream response. Each dispatched event
+// carries its type as the Event header and the last seen id as
+// Last-Event-Id, so reconnects can resume where the stream left off.
+type sseConn struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+	cancel context.CancelFunc
+
+	lastEventID string
+}
+
+func dialSSE(ctx context.Context, url string, header http.Header) (Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header = header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		cancel()
+		return nil, errors.New("Event stream answered with status " + strconv.Itoa(res.StatusCode))
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		res.Body.Close()
+		cancel()
+		return nil, errors.New("Not an event stream: " + res.Header.Get("Content-Type"))
+	}
+
+	return &sseConn{
+		body:        res.Body,
+		reader:      bufio.NewReader(res.Body),
+		cancel:      cancel,
+		lastEventID: header.Get("Last-Event-Id"),
+	}, nil
+}
+
+func (c *sseConn) Next() (Event, error) {
+	var eventType string
+	var data strings.Builder
+	hasData := false
+
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return Event{}, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if !hasData {
+				// events without data are not dispatched
+				eventType = ""
+				continue
+			}
+			header := http.Header{}
+			if eventType == "" {
+				eventType = "message"
+			}
+			header.Set("Event", eventType)
+			if c.lastEventID != "" {
+				header.Set("Last-Event-Id", c.lastEventID)
+			}
+			body := strings.TrimSuffix(data.String(), "\n")
+			return Event{Header: header, Body: []byte(body), Time: time.Now()}, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, usually a keep-alive
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				c.lastEventID = value
+			}
+		}
+	}
+}
+
+func (c *sseConn) Close() error {
+	c.cancel()
+	return c.body.Close()
+}
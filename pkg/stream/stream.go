@@ -0,0 +1,49 @@
+// Package stream reads events from long-lived connections opened to a
+// provider, for providers that announce completions instead of calling a
+// url.
+package stream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	KindWebSocket = "websocket"
+	KindSSE       = "sse"
+)
+
+// Event is a single message read from a stream.
+type Event struct {
+	// Header holds the event's metadata, eg. the sse event type and id
+	Header http.Header
+	Body   []byte
+	// Time is when the event was read
+	Time time.Time
+}
+
+// Conn is an open stream.
+type Conn interface {
+	// Next blocks until the next event arrives, an error means the
+	// connection is gone.
+	Next() (Event, error)
+	// Close ends the connection and unblocks Next.
+	Close() error
+}
+
+// Dial opens a stream of kind to url, sending header with the handshake.
+func Dial(ctx context.Context, kind, url string, header http.Header) (Conn, error) {
+	if url == "" {
+		return nil, errors.New("Stream url is missing")
+	}
+
+	switch kind {
+	case KindWebSocket:
+		return dialWebSocket(ctx, url, header)
+	case KindSSE:
+		return dialSSE(ctx, url, header)
+	}
+	return nil, errors.New("Unknown stream kind: " + kind)
+}
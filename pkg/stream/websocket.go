@@ -0,0 +1,40 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// webSocketConn turns every text or binary message into an event, pings
+// are answered by the library.
+type webSocketConn struct {
+	conn *websocket.Conn
+}
+
+func dialWebSocket(ctx context.Context, url string, header http.Header) (Conn, error) {
+	conn, res, err := websocket.DefaultDialer.DialContext(ctx, url, header)
+	if err != nil {
+		if res != nil {
+			return nil, errors.New("Websocket handshake failed with status " + strconv.Itoa(res.StatusCode))
+		}
+		return nil, err
+	}
+	return &webSocketConn{conn: conn}, nil
+}
+
+func (c *webSocketConn) Next() (Event, error) {
+	_, body, err := c.conn.ReadMessage()
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{Header: http.Header{}, Body: body, Time: time.Now()}, nil
+}
+
+func (c *webSocketConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,264 @@
+package tracker
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	recordsBucket      = []byte("records")
+	orphansBucket      = []byte("orphans")
+	orphanCountsBucket = []byte("orphanCounts")
+	metaBucket         = []byte("meta")
+)
+
+// BoltStore keeps records in a bolt file instead of memory, so runs of
+// millions of requests don't have to fit in RAM and the file can still be
+// reported on after the tester crashed.
+//
+// Writes are not fsynced until Close: a crash of the tester loses nothing,
+// a power cut can lose the file.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// CreateBoltStore creates an empty store at path, replacing any store a
+// previous run left there.
+func CreateBoltStore(path string) (*BoltStore, error) {
+	if path == "" {
+		return nil, errors.New("Bolt tracker store needs a path")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0o644, &bolt.Options{
+		Timeout:        time.Second,
+		NoSync:         true,
+		NoFreelistSync: true,
+		FreelistType:   bolt.FreelistMapType,
+	})
+	if err != nil {
+		return nil, errors.New("Could not create tracker store " + path + ": " + err.Error())
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{recordsBucket, orphansBucket, orphanCountsBucket, metaBucket} {
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// OpenBoltStore opens the store a previous run left at path for reading.
+// Writes to it fail.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, errors.New("Could not find tracker store: " + path)
+	}
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, errors.New("Could not open tracker store " + path + ": " + err.Error())
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Get(key string) RequestTrackerPair {
+	var value RequestTrackerPair
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(recordsBucket).Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+		return json.Unmarshal(raw, &value)
+	})
+	if err != nil {
+		slog.Error("Failed to read record", "key", key, "err", err)
+	}
+	return value
+}
+
+func (s *BoltStore) Set(key string, value RequestTrackerPair) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return putRecord(tx, key, value)
+	})
+	if err != nil {
+		slog.Error("Failed to write record", "key", key, "err", err)
+	}
+}
+
+// Update applies fn to the record stored under key in a single
+// transaction, bolt runs one at a time so concurrent updates can't lose
+// each other's changes.
+func (s *BoltStore) Update(key string, fn func(*RequestTrackerPair)) bool {
+	found := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(recordsBucket).Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+		var value RequestTrackerPair
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		found = true
+		fn(&value)
+		return putRecord(tx, key, value)
+	})
+	if err != nil {
+		slog.Error("Failed to update record", "key", key, "err", err)
+		return false
+	}
+	return found
+}
+
+func putRecord(tx *bolt.Tx, key string, value RequestTrackerPair) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(recordsBucket).Put([]byte(key), raw)
+}
+
+// Range reads the records one at a time from a single read transaction,
+// so only the record being visited is held in memory.
+func (s *BoltStore) Range(fn func(key string, value RequestTrackerPair) bool) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(recordsBucket).Cursor()
+		for key, raw := c.First(); key != nil; key, raw = c.Next() {
+			var value RequestTrackerPair
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return errors.New("Corrupt record " + string(key) + ": " + err.Error())
+			}
+			if !fn(string(key), value) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Len() int {
+	n := 0
+	s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(recordsBucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// AddOrphan counts orphan by reason and keeps it as a sample while there
+// are fewer than MaxOrphanSamples.
+func (s *BoltStore) AddOrphan(orphan Orphan) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		counts := tx.Bucket(orphanCountsBucket)
+		count := make([]byte, 8)
+		if raw := counts.Get([]byte(orphan.Reason)); raw != nil {
+			binary.BigEndian.PutUint64(count, binary.BigEndian.Uint64(raw)+1)
+		} else {
+			binary.BigEndian.PutUint64(count, 1)
+		}
+		if err := counts.Put([]byte(orphan.Reason), count); err != nil {
+			return err
+		}
+
+		// the sequence only moves for samples, so it counts them
+		bucket := tx.Bucket(orphansBucket)
+		if bucket.Sequence() >= uint64(MaxOrphanSamples) {
+			return nil
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		raw, err := json.Marshal(sampleOrphan(orphan))
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return bucket.Put(key, raw)
+	})
+	if err != nil {
+		slog.Error("Failed to write orphan", "err", err)
+	}
+}
+
+// Orphans returns the orphan samples in the order they arrived.
+func (s *BoltStore) Orphans() []Orphan {
+	var orphans []Orphan
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(orphansBucket).ForEach(func(_, raw []byte) error {
+			var orphan Orphan
+			if err := json.Unmarshal(raw, &orphan); err != nil {
+				return err
+			}
+			orphans = append(orphans, orphan)
+			return nil
+		})
+	})
+	if err != nil {
+		slog.Error("Failed to read orphans", "err", err)
+	}
+	return orphans
+}
+
+// OrphanCounts returns how many orphans arrived per reason, samples or
+// not.
+func (s *BoltStore) OrphanCounts() map[string]int {
+	counts := make(map[string]int)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(orphanCountsBucket).ForEach(func(reason, raw []byte) error {
+			counts[string(reason)] = int(binary.BigEndian.Uint64(raw))
+			return nil
+		})
+	})
+	if err != nil {
+		slog.Error("Failed to read orphan counts", "err", err)
+	}
+	return counts
+}
+
+// SetMeta stores value under key next to the records, eg. the config of
+// the run so it can be reported on later.
+func (s *BoltStore) SetMeta(key string, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put([]byte(key), value)
+	})
+}
+
+// Meta returns what SetMeta stored under key, nil when nothing was.
+func (s *BoltStore) Meta(key string) []byte {
+	var value []byte
+	s.db.View(func(tx *bolt.Tx) error {
+		if raw := tx.Bucket(metaBucket).Get([]byte(key)); raw != nil {
+			value = append([]byte(nil), raw...)
+		}
+		return nil
+	})
+	return value
+}
+
+// Close flushes the store to disk and closes it.
+func (s *BoltStore) Close() error {
+	if s.db.IsReadOnly() {
+		return s.db.Close()
+	}
+	return errors.Join(s.db.Sync(), s.db.Close())
+}
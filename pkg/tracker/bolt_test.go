@@ -0,0 +1,138 @@
+package tracker
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBoltStore_ReopenAfterRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs", "run.db")
+	store, err := CreateBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Round(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("req-%d", i)
+		store.Set(key, RequestTrackerPair{StartTime: start, Tags: map[string]string{"env": "test"}})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for attempt := 0; attempt < 3; attempt++ {
+				store.Update(key, func(r *RequestTrackerPair) {
+					r.Attempts = append(r.Attempts, Attempt{Time: start, Status: 200})
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if store.Update("unknown", func(*RequestTrackerPair) {}) {
+		t.Error("Expected updating an unknown key to report false")
+	}
+	store.AddOrphan(Orphan{Reason: OrphanUnknownCorrelationID, Body: "first"})
+	store.AddOrphan(Orphan{Reason: OrphanMalformedBody, Body: "second"})
+	if err := store.SetMeta("config", []byte("run: {}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if reopened.Len() != 50 {
+		t.Fatalf("Expected 50 records, got %d", reopened.Len())
+	}
+	visited := 0
+	err = reopened.Range(func(key string, value RequestTrackerPair) bool {
+		visited++
+		if len(value.Attempts) != 3 || !value.StartTime.Equal(start) || value.Tags["env"] != "test" {
+			t.Errorf("%s: expected every update to survive, got %+v", key, value)
+		}
+		return true
+	})
+	if err != nil || visited != 50 {
+		t.Errorf("Expected to visit 50 records, visited %d: %v", visited, err)
+	}
+
+	if orphans := reopened.Orphans(); len(orphans) != 2 || orphans[0].Body != "first" || orphans[1].Body != "second" {
+		t.Errorf("Expected both orphans in order, got %+v", orphans)
+	}
+	if counts := reopened.OrphanCounts(); counts[OrphanUnknownCorrelationID] != 1 || counts[OrphanMalformedBody] != 1 {
+		t.Errorf("Expected orphan counts to survive, got %v", counts)
+	}
+	if string(reopened.Meta("config")) != "run: {}" || reopened.Meta("missing") != nil {
+		t.Errorf("Unexpected meta %q", reopened.Meta("config"))
+	}
+}
+
+func TestBoltStore_CreateReplacesPreviousRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.db")
+	store, err := CreateBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("old", RequestTrackerPair{})
+	store.Close()
+
+	store, err = CreateBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if store.Len() != 0 {
+		t.Errorf("Expected an empty store, got %d records", store.Len())
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if store, err := Open("", ""); err != nil {
+		t.Errorf("Expected memory by default, got %v", err)
+	} else if _, ok := store.(*Tracker); !ok {
+		t.Errorf("Expected an in-memory tracker, got %T", store)
+	}
+	if _, err := Open(StoreBolt, ""); err == nil {
+		t.Error("Expected a bolt store without a path to be refused")
+	}
+	if _, err := Open("redis", ""); err == nil {
+		t.Error("Expected an unknown store to be refused")
+	}
+}
+
+func BenchmarkBoltStore_Update(b *testing.B) {
+	store, err := CreateBoltStore(filepath.Join(b.TempDir(), "run.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer store.Close()
+
+	keys := benchmarkKeys(10_000)
+	for _, key := range keys {
+		store.Set(key, RequestTrackerPair{StartTime: time.Now()})
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Update(keys[i%len(keys)], func(r *RequestTrackerPair) {
+			r.EndTime = time.Now()
+		})
+	}
+}
+
+func TestBoltStore_OrphansAreBounded(t *testing.T) {
+	store, err := CreateBoltStore(filepath.Join(t.TempDir(), "run.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	checkOrphanBounds(t, store)
+}
@@ -0,0 +1,58 @@
+package tracker
+
+import "errors"
+
+const (
+	StoreMemory = "memory"
+	StoreBolt   = "bolt"
+)
+
+// Store keeps a record per request and the orphans of a run. Tracker keeps
+// them in memory, BoltStore on disk for runs too large to fit.
+type Store interface {
+	Get(key string) RequestTrackerPair
+	Set(key string, value RequestTrackerPair)
+	// Update applies fn to the record stored under key, returning false
+	// when there is none
+	Update(key string, fn func(*RequestTrackerPair)) bool
+	// Range calls fn for every record, one at a time, until fn returns
+	// false. fn must not call back into the store.
+	Range(fn func(key string, value RequestTrackerPair) bool) error
+	// Len counts the records
+	Len() int
+	// AddOrphan counts orphan and keeps it if it is among the first
+	// MaxOrphanSamples
+	AddOrphan(orphan Orphan)
+	// Orphans returns the kept samples, in the order they arrived
+	Orphans() []Orphan
+	// OrphanCounts counts every orphan by reason
+	OrphanCounts() map[string]int
+	Close() error
+}
+
+// Open returns an empty store of kind, path is where bolt stores are
+// written. Kind defaults to memory.
+func Open(kind string, path string) (Store, error) {
+	switch kind {
+	case "", StoreMemory:
+		return NewRequestTracker(), nil
+	case StoreBolt:
+		return CreateBoltStore(path)
+	}
+	return nil, errors.New("Unknown tracker store: " + kind)
+}
+
+// Snapshot copies every record of s into a map.
+func Snapshot(s Store) (map[string]RequestTrackerPair, error) {
+	all := make(map[string]RequestTrackerPair, s.Len())
+	err := s.Range(func(key string, value RequestTrackerPair) bool {
+		all[key] = value
+		return true
+	})
+	return all, err
+}
+
+var (
+	_ Store = (*Tracker)(nil)
+	_ Store = (*BoltStore)(nil)
+)
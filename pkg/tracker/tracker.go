@@ -1,38 +1,341 @@
 package tracker
 
 import (
+	"maps"
 	"sync"
 	"time"
 )
 
+// Attempt is a single callback delivery and the status it was answered with.
+type Attempt struct {
+	Time   time.Time
+	Status int // 0 when the receiver hung up on purpose
+}
+
+// AssertionFailure is an expectation a callback didn't meet.
+type AssertionFailure struct {
+	Rule    string
+	Message string
+}
+
+// Outcome is how a request ended.
+type Outcome string
+
+const (
+	OutcomeOK               Outcome = "ok"
+	OutcomeSkipped          Outcome = "skipped"
+	OutcomeTriggerFailed    Outcome = "trigger-failed"
+	OutcomeTimedOut         Outcome = "timed-out"
+	OutcomeInvalidSignature Outcome = "invalid-signature"
+	OutcomeAssertionFailed  Outcome = "assertion-failed"
+)
+
+// Outcomes lists every outcome in the order reports show them.
+var Outcomes = []Outcome{
+	OutcomeOK,
+	OutcomeSkipped,
+	OutcomeTriggerFailed,
+	OutcomeTimedOut,
+	OutcomeInvalidSignature,
+	OutcomeAssertionFailed,
+}
+
+// NoCallbackError is the error of requests still waiting for a callback
+// when the run stopped waiting.
+const NoCallbackError = "no callback within timeout"
+
 type RequestTrackerPair struct {
-	StartTime time.Time // start
-	EndTime   time.Time
+	ScheduledTime time.Time // when the request should have been fired
+	StartTime     time.Time // when sending started
+	SendEndTime   time.Time // when the trigger returned
+	EndTime       time.Time // when the first accepted callback arrived
+	Skipped       bool      // dropped because too many requests were in flight
+	SendFailed    bool      // the trigger itself failed, Error says why
+	StatusCode    int       // http status the target answered with, when known
+
+	Scenario string            // name of the test the request belongs to
+	Tags     map[string]string // labels of the test, eg. env: staging
+
+	RequestBytes  int // size of the trigger payload
+	ResponseBytes int // size of the synchronous response
+	CallbackBytes int // size of the first accepted callback
+
+	Attempts []Attempt // every callback delivery, accepted or not
+
+	// EndTime is the first accepted delivery, the ones after it are
+	// redeliveries
+	Redeliveries []time.Time
+	DeliveryIDs  []string // distinct delivery ids seen
+	Duplicates   int      // redeliveries of a delivery id already seen
+
+	AssertionFailures []AssertionFailure // expectations callbacks didn't meet
+
+	InvalidSignature bool   // callback failed signature verification
+	Error            string // why the request failed, empty when it didn't
+}
+
+// Outcome classifies the record. Requests still waiting for a callback
+// count as timed out, so it is only meaningful once the run is over.
+func (p RequestTrackerPair) Outcome() Outcome {
+	switch {
+	case p.Skipped:
+		return OutcomeSkipped
+	case p.SendFailed:
+		return OutcomeTriggerFailed
+	case p.EndTime.IsZero():
+		return OutcomeTimedOut
+	case p.InvalidSignature:
+		return OutcomeInvalidSignature
+	case len(p.AssertionFailures) > 0:
+		return OutcomeAssertionFailed
+	}
+	return OutcomeOK
+}
+
+// SyncLatency is how long the trigger took to return, 0 when it never did.
+func (p RequestTrackerPair) SyncLatency() time.Duration {
+	if p.SendEndTime.IsZero() {
+		return 0
+	}
+	return p.SendEndTime.Sub(p.StartTime)
 }
 
+// CallbackLatency is the time from sending to the first accepted callback,
+// 0 when none arrived.
+func (p RequestTrackerPair) CallbackLatency() time.Duration {
+	if p.EndTime.IsZero() {
+		return 0
+	}
+	return p.EndTime.Sub(p.StartTime)
+}
+
+// CallbackCount counts the accepted deliveries, redeliveries included.
+func (p RequestTrackerPair) CallbackCount() int {
+	if p.EndTime.IsZero() {
+		return 0
+	}
+	return 1 + len(p.Redeliveries)
+}
+
+// AttemptCount counts every delivery, accepted or not.
+func (p RequestTrackerPair) AttemptCount() int {
+	return len(p.Attempts)
+}
+
+const (
+	OrphanMalformedBody        = "malformed body"
+	OrphanMissingCorrelationID = "missing correlation id"
+	OrphanInvalidCorrelationID = "correlation id not a string"
+	OrphanUnknownCorrelationID = "unknown correlation id"
+)
+
+// Orphan is a callback that could not be matched to a request.
+type Orphan struct {
+	Time    time.Time
+	Reason  string
+	Headers map[string][]string
+	Body    string
+}
+
+// MaxOrphanSamples bounds how many orphans stores keep whole. Later ones
+// are only counted by reason, so a flood of misrouted callbacks doesn't
+// grow memory.
+var MaxOrphanSamples = 5
+
+// MaxOrphanBodyBytes bounds the body kept of an orphan sample.
+var MaxOrphanBodyBytes = 4096
+
+// sampleOrphan is orphan with its body cut to MaxOrphanBodyBytes.
+func sampleOrphan(orphan Orphan) Orphan {
+	if len(orphan.Body) > MaxOrphanBodyBytes {
+		orphan.Body = orphan.Body[:MaxOrphanBodyBytes]
+	}
+	return orphan
+}
+
+// Disconnection is a dropped connection to a stream the provider announces
+// completions on.
+type Disconnection struct {
+	Time   time.Time
+	Reason string
+	// Downtime lasts until the connection was back, or until the run
+	// ended when it never was
+	Downtime  time.Duration
+	Recovered bool
+}
+
+// shardCount splits the records over this many maps, each with its own
+// lock, so callbacks for different requests rarely wait on each other.
+const shardCount = 64
+
+type shard struct {
+	lock    sync.RWMutex
+	records map[string]RequestTrackerPair
+}
+
+// Tracker keeps a record per request. It is safe for concurrent use.
 type Tracker struct {
-	reqTracker map[string]RequestTrackerPair
-	lock       sync.RWMutex
+	shards [shardCount]shard
+
+	orphansLock  sync.RWMutex
+	orphans      []Orphan
+	orphanCounts map[string]int
 }
 
 func NewRequestTracker() *Tracker {
-	return &Tracker{
-		reqTracker: make(map[string]RequestTrackerPair),
+	t := &Tracker{orphanCounts: make(map[string]int)}
+	for i := range t.shards {
+		t.shards[i].records = make(map[string]RequestTrackerPair)
+	}
+	return t
+}
+
+// shardFor picks the shard of key with an inlined fnv-1a hash.
+func (t *Tracker) shardFor(key string) *shard {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
 	}
+	return &t.shards[h%shardCount]
 }
 
+// GetAll returns a copy of every record.
+//
+// Deprecated: use Snapshot or Records.
 func (t *Tracker) GetAll() map[string]RequestTrackerPair {
-	return t.reqTracker
+	return t.Snapshot()
+}
+
+// Snapshot copies every record as of a single point in time, every shard
+// is held while copying so writes can't land half way through.
+func (t *Tracker) Snapshot() map[string]RequestTrackerPair {
+	t.lockAll()
+	defer t.unlockAll()
+
+	all := make(map[string]RequestTrackerPair, t.len())
+	for i := range t.shards {
+		for key, value := range t.shards[i].records {
+			all[key] = value
+		}
+	}
+	return all
+}
+
+// Records is Snapshot without the keys.
+func (t *Tracker) Records() []RequestTrackerPair {
+	t.lockAll()
+	defer t.unlockAll()
+
+	records := make([]RequestTrackerPair, 0, t.len())
+	for i := range t.shards {
+		for _, value := range t.shards[i].records {
+			records = append(records, value)
+		}
+	}
+	return records
+}
+
+// Range calls fn for every record of a snapshot, so fn is free to call
+// back into the tracker.
+func (t *Tracker) Range(fn func(key string, value RequestTrackerPair) bool) error {
+	for key, value := range t.Snapshot() {
+		if !fn(key, value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Close is a no-op, the records stay readable.
+func (t *Tracker) Close() error {
+	return nil
+}
+
+// Len counts the records.
+func (t *Tracker) Len() int {
+	t.lockAll()
+	defer t.unlockAll()
+	return t.len()
+}
+
+func (t *Tracker) len() int {
+	n := 0
+	for i := range t.shards {
+		n += len(t.shards[i].records)
+	}
+	return n
+}
+
+// lockAll read locks every shard, always in the same order so two
+// snapshots can't deadlock.
+func (t *Tracker) lockAll() {
+	for i := range t.shards {
+		t.shards[i].lock.RLock()
+	}
+}
+
+func (t *Tracker) unlockAll() {
+	for i := range t.shards {
+		t.shards[i].lock.RUnlock()
+	}
 }
 
 func (t *Tracker) Get(key string) RequestTrackerPair {
-	t.lock.RLock()
-	defer t.lock.RUnlock()
-	return t.reqTracker[key]
+	s := t.shardFor(key)
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.records[key]
 }
+
 func (t *Tracker) Set(key string, value RequestTrackerPair) {
-	t.lock.Lock()
-	defer t.lock.Unlock()
+	s := t.shardFor(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.records[key] = value
+}
+
+// Update applies fn to the record stored under key while holding the lock,
+// so concurrent read-modify-write cycles don't lose each other's changes.
+// Unknown keys are left alone and reported by returning false.
+func (t *Tracker) Update(key string, fn func(*RequestTrackerPair)) bool {
+	s := t.shardFor(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, found := s.records[key]
+	if !found {
+		return false
+	}
+	fn(&value)
+	s.records[key] = value
+	return true
+}
+
+// AddOrphan counts orphan by reason and keeps it as a sample while there
+// are fewer than MaxOrphanSamples.
+func (t *Tracker) AddOrphan(orphan Orphan) {
+	t.orphansLock.Lock()
+	defer t.orphansLock.Unlock()
+
+	t.orphanCounts[orphan.Reason]++
+	if len(t.orphans) < MaxOrphanSamples {
+		t.orphans = append(t.orphans, sampleOrphan(orphan))
+	}
+}
+
+// Orphans returns a copy of the orphan samples recorded so far.
+func (t *Tracker) Orphans() []Orphan {
+	t.orphansLock.RLock()
+	defer t.orphansLock.RUnlock()
+	return append([]Orphan(nil), t.orphans...)
+}
 
-	t.reqTracker[key] = value
+// OrphanCounts returns how many orphans arrived per reason, samples or
+// not.
+func (t *Tracker) OrphanCounts() map[string]int {
+	t.orphansLock.RLock()
+	defer t.orphansLock.RUnlock()
+	return maps.Clone(t.orphanCounts)
 }
@@ -0,0 +1,242 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOutcome(t *testing.T) {
+	start := time.Now()
+	done := start.Add(time.Second)
+
+	cases := []struct {
+		name   string
+		record RequestTrackerPair
+		want   Outcome
+	}{
+		{"called back", RequestTrackerPair{StartTime: start, EndTime: done}, OutcomeOK},
+		{"skipped", RequestTrackerPair{Skipped: true}, OutcomeSkipped},
+		{"send failed", RequestTrackerPair{StartTime: start, SendFailed: true, Error: "trigger failed: refused"}, OutcomeTriggerFailed},
+		{"no callback", RequestTrackerPair{StartTime: start, SendEndTime: start}, OutcomeTimedOut},
+		{"wrongly signed", RequestTrackerPair{StartTime: start, EndTime: done, InvalidSignature: true}, OutcomeInvalidSignature},
+		{"broke a rule", RequestTrackerPair{StartTime: start, EndTime: done, AssertionFailures: []AssertionFailure{{Rule: "status"}}}, OutcomeAssertionFailed},
+		// a late callback doesn't make up for a failed send
+		{"send failed, called back", RequestTrackerPair{StartTime: start, EndTime: done, SendFailed: true}, OutcomeTriggerFailed},
+	}
+	for _, c := range cases {
+		if got := c.record.Outcome(); got != c.want {
+			t.Errorf("%s: expected %s, got %s", c.name, c.want, got)
+		}
+	}
+}
+
+func TestDerivedTimings(t *testing.T) {
+	start := time.Now()
+	record := RequestTrackerPair{
+		StartTime:    start,
+		SendEndTime:  start.Add(20 * time.Millisecond),
+		EndTime:      start.Add(time.Second),
+		Attempts:     []Attempt{{Status: 500}, {Status: 200}, {Status: 200}},
+		Redeliveries: []time.Time{start.Add(2 * time.Second)},
+	}
+	if got := record.SyncLatency(); got != 20*time.Millisecond {
+		t.Errorf("Expected a 20ms sync latency, got %s", got)
+	}
+	if got := record.CallbackLatency(); got != time.Second {
+		t.Errorf("Expected a 1s callback latency, got %s", got)
+	}
+	if record.CallbackCount() != 2 || record.AttemptCount() != 3 {
+		t.Errorf("Expected 2 callbacks out of 3 attempts, got %d of %d", record.CallbackCount(), record.AttemptCount())
+	}
+
+	pending := RequestTrackerPair{StartTime: start}
+	if pending.SyncLatency() != 0 || pending.CallbackLatency() != 0 || pending.CallbackCount() != 0 {
+		t.Errorf("Expected no timings for a request still in flight, got %+v", pending)
+	}
+}
+
+func TestTracker_ConcurrentUpdates(t *testing.T) {
+	tr := NewRequestTracker()
+	const keys, workers, updates = 100, 8, 500
+	for i := 0; i < keys; i++ {
+		tr.Set(fmt.Sprint("req-", i), RequestTrackerPair{})
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < updates; i++ {
+				tr.Update(fmt.Sprint("req-", (w*updates+i)%keys), func(r *RequestTrackerPair) {
+					r.Duplicates++
+				})
+				if i%50 == 0 {
+					tr.Snapshot()
+					tr.AddOrphan(Orphan{Reason: OrphanUnknownCorrelationID})
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, record := range tr.Records() {
+		total += record.Duplicates
+	}
+	if total != workers*updates {
+		t.Errorf("Expected %d updates, got %d", workers*updates, total)
+	}
+	if tr.Len() != keys {
+		t.Errorf("Expected %d records, got %d", keys, tr.Len())
+	}
+	if counted := tr.OrphanCounts()[OrphanUnknownCorrelationID]; counted != workers*updates/50 {
+		t.Errorf("Expected %d orphans, got %d", workers*updates/50, counted)
+	}
+	if len(tr.Orphans()) != MaxOrphanSamples {
+		t.Errorf("Expected %d orphan samples, got %d", MaxOrphanSamples, len(tr.Orphans()))
+	}
+	if tr.Update("unknown", func(*RequestTrackerPair) {}) {
+		t.Error("Expected unknown keys to be left alone")
+	}
+}
+
+// TestTracker_SnapshotIsConsistent adds records one after the other, a
+// point in time snapshot must therefore hold a gapless prefix of them.
+func TestTracker_SnapshotIsConsistent(t *testing.T) {
+	tr := NewRequestTracker()
+	const n = 5000
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < n; i++ {
+			tr.Set(fmt.Sprint("req-", i), RequestTrackerPair{Duplicates: i})
+		}
+	}()
+
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		snapshot := tr.Snapshot()
+		for i := 0; i < len(snapshot); i++ {
+			if _, found := snapshot[fmt.Sprint("req-", i)]; !found {
+				t.Fatalf("Snapshot of %d records is missing req-%d", len(snapshot), i)
+			}
+		}
+	}
+}
+
+// lockedMap is the single lock tracker the sharded one replaced, kept to
+// compare against.
+type lockedMap struct {
+	lock    sync.RWMutex
+	records map[string]RequestTrackerPair
+}
+
+func (m *lockedMap) Update(key string, fn func(*RequestTrackerPair)) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	value, found := m.records[key]
+	if !found {
+		return false
+	}
+	fn(&value)
+	m.records[key] = value
+	return true
+}
+
+func benchmarkKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%08x-6c2e-4a7b-9d1f-%012x", i, i)
+	}
+	return keys
+}
+
+func BenchmarkTracker_UpdateParallel(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	tr := NewRequestTracker()
+	for _, key := range keys {
+		tr.Set(key, RequestTrackerPair{})
+	}
+
+	var next atomic.Uint64
+	b.RunParallel(func(pb *testing.PB) {
+		i := next.Add(1) * 7919
+		for pb.Next() {
+			i++
+			tr.Update(keys[i%uint64(len(keys))], func(r *RequestTrackerPair) { r.Duplicates++ })
+		}
+	})
+}
+
+func BenchmarkLockedMap_UpdateParallel(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	m := &lockedMap{records: make(map[string]RequestTrackerPair)}
+	for _, key := range keys {
+		m.records[key] = RequestTrackerPair{}
+	}
+
+	var next atomic.Uint64
+	b.RunParallel(func(pb *testing.PB) {
+		i := next.Add(1) * 7919
+		for pb.Next() {
+			i++
+			m.Update(keys[i%uint64(len(keys))], func(r *RequestTrackerPair) { r.Duplicates++ })
+		}
+	})
+}
+
+func BenchmarkTracker_Snapshot(b *testing.B) {
+	tr := NewRequestTracker()
+	for _, key := range benchmarkKeys(10000) {
+		tr.Set(key, RequestTrackerPair{})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Snapshot()
+	}
+}
+
+// checkOrphanBounds floods store with orphans and checks that all of them
+// are counted while only the first few are kept, bodies cut short.
+func checkOrphanBounds(t *testing.T, store Store) {
+	t.Helper()
+	body := strings.Repeat("x", MaxOrphanBodyBytes+100)
+	for i := 0; i < 1000; i++ {
+		reason := OrphanUnknownCorrelationID
+		if i%4 == 0 {
+			reason = OrphanMalformedBody
+		}
+		store.AddOrphan(Orphan{Reason: reason, Headers: map[string][]string{"X-Seq": {fmt.Sprint(i)}}, Body: body})
+	}
+
+	counts := store.OrphanCounts()
+	if counts[OrphanUnknownCorrelationID] != 750 || counts[OrphanMalformedBody] != 250 {
+		t.Errorf("Expected every orphan counted by reason, got %v", counts)
+	}
+	samples := store.Orphans()
+	if len(samples) != MaxOrphanSamples {
+		t.Fatalf("Expected %d samples, got %d", MaxOrphanSamples, len(samples))
+	}
+	for i, sample := range samples {
+		if sample.Headers["X-Seq"][0] != fmt.Sprint(i) {
+			t.Errorf("Expected the first orphans as samples, got %v at %d", sample.Headers, i)
+		}
+		if len(sample.Body) != MaxOrphanBodyBytes {
+			t.Errorf("Expected bodies cut to %d bytes, got %d", MaxOrphanBodyBytes, len(sample.Body))
+		}
+	}
+}
+
+func TestTracker_OrphansAreBounded(t *testing.T) {
+	checkOrphanBounds(t, NewRequestTracker())
+}
@@ -6,4 +6,8 @@ var (
 	TimedOutWaitingForResultsErr = errors.New("timed out waiting for results")
 	UnsupportedOutputErr         = errors.New("Unsupported output format")
 	NgrokAuthMissingErr          = errors.New("Ngrok auth token missing from environment. Please set NGROK_AUTHTOKEN to use ngrok")
+	TimedOutWaitingForWorkersErr = errors.New("timed out waiting for workers")
+	MissingSignatureErr          = errors.New("signature missing")
+	InvalidSignatureErr          = errors.New("signature invalid")
+	SignatureTimestampErr        = errors.New("signature timestamp outside tolerance")
 )
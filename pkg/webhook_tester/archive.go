@@ -0,0 +1,89 @@
+package webhook_tester
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/archive"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// archiveTrigger records a trigger along with whatever came back from the
+// api under test.
+func (wt *DefaultWebhookTester) archiveTrigger(msg *TriggerMessage, started time.Time, res *TriggerResponse, err error) {
+	a := wt.internal.archive
+	if a == nil {
+		return
+	}
+
+	payload, _ := msg.Payload()
+	request := a.NewMessage(msg.Header, payload)
+	request.URL = wt.internal.trigger.Target()
+	if triggerType(wt.config) == TriggerHTTP {
+		request.Method = http.MethodPost
+	}
+
+	entry := archive.Entry{
+		Kind:          archive.KindTrigger,
+		CorrelationID: msg.CorrelationID,
+		StartedAt:     started,
+		FinishedAt:    time.Now(),
+		Request:       request,
+	}
+	if res != nil {
+		response := a.NewMessage(res.Header, res.Body)
+		response.Status = res.Status
+		entry.Response = &response
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	wt.record(entry)
+}
+
+// archiveCallback records a received callback and the reply it was given,
+// a zero status meaning the connection was left hanging. Callbacks that
+// can't be replied to are archived without a response.
+func (wt *DefaultWebhookTester) archiveCallback(correlationId string, cb *Callback, reply types.ResponseRule, orphanReason string) {
+	a := wt.internal.archive
+	if a == nil {
+		return
+	}
+
+	request := a.NewMessage(cb.Header, cb.Body)
+	request.Method = cb.Method
+	request.URL = cb.Source
+
+	entry := archive.Entry{
+		Kind:          archive.KindCallback,
+		CorrelationID: correlationId,
+		StartedAt:     cb.ReceivedAt,
+		FinishedAt:    time.Now(),
+		Request:       request,
+		OrphanReason:  orphanReason,
+	}
+	if cb.Reply != nil {
+		response := a.NewMessage(headerOf(reply.Headers), []byte(reply.Body))
+		response.Status = reply.Status
+		if reply.Hang {
+			response.Status = 0
+		}
+		entry.Response = &response
+	}
+	wt.record(entry)
+}
+
+func (wt *DefaultWebhookTester) record(entry archive.Entry) {
+	if err := wt.internal.archive.Record(entry); err != nil {
+		slog.Error("Failed to archive exchange", "kind", entry.Kind, "err", err)
+	}
+}
+
+func headerOf(values map[string]string) http.Header {
+	header := http.Header{}
+	for k, v := range values {
+		header.Set(k, v)
+	}
+	return header
+}
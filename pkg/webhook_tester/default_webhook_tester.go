@@ -1,39 +1,51 @@
 package webhook_tester
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"io"
-	"log"
 	"log/slog"
 	"net/http"
-	"os"
-	"os/signal"
+	"slices"
+	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/archive"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/assertions"
 	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/scheduler"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/signature"
 	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
 	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
-	"golang.ngrok.com/ngrok"
-	"golang.ngrok.com/ngrok/config"
 )
 
 var DEFAULT_WAITING_TIMEOUT = time.Duration(30) * time.Second
 
+const DEFAULT_RECEIVER_LISTEN = ":8081"
+
 type internalConfig struct {
 	targetUrl     string
 	selfUrl       string
 	selfUrlChan   chan string
 	requestsFired chan bool
 
-	iterGap    time.Duration
-	requestWg  sync.WaitGroup
-	reqTracker *tracker.Tracker
+	iterGap      time.Duration
+	arrival      scheduler.Arrival
+	pool         *scheduler.Pool
+	signature    signature.Scheme
+	responseRand *responseRand
+	expect       []*assertions.Rule
+	archive      *archive.Archive
+	trigger      Trigger
+	receiver     Receiver
+	requestWg    sync.WaitGroup
+	reqTracker   tracker.Store
+
+	// correlationIds are handed out by a distributed controller, when empty
+	// fresh ids are generated for every request
+	correlationIds []string
 }
 
 type DefaultWebhookTester struct {
@@ -41,28 +53,111 @@ type DefaultWebhookTester struct {
 	internal *internalConfig
 }
 
-func (wt *DefaultWebhookTester) receiverHandler(w http.ResponseWriter, r *http.Request) {
-	bytedata, _ := io.ReadAll(r.Body)
+// handleCallback matches a callback to its request, records it and replies
+// to it.
+func (wt *DefaultWebhookTester) handleCallback(cb *Callback) {
+	bytedata := cb.Body
 	reqBodyStr := string(bytedata)
 	slog.Debug("Received New Message", "body", reqBodyStr)
 	// pick correlationId
 	// save in common concurrent hashmap
 	var resMap map[string]any
-	err := json.Unmarshal(bytedata, &resMap)
-	if err != nil {
-		panic(err)
+	bodyErr := json.Unmarshal(bytedata, &resMap)
+
+	correlationId, orphanReason := wt.pickCorrelationID(cb.Header, resMap, bodyErr)
+	if orphanReason != "" {
+		wt.recordOrphan(cb, orphanReason)
+		return
+	}
+	// callbacks may have queued up before reaching here, time them by when
+	// the receiver read them
+	endTime := cb.ReceivedAt
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+
+	var signatureErr error
+	if wt.internal.signature != nil {
+		signatureErr = wt.internal.signature.Verify(cb.Header, bytedata, endTime)
+		if signatureErr != nil {
+			slog.Debug("Callback signature rejected", "key", correlationId, "err", signatureErr)
+		}
 	}
-	var correlationId string
-	if wt.config.Test.Pickers.CorrelationPicker.GetRootType() == types.RootBody {
-		// TODO: Add error handling here, what if it's not found?
-		correlationId = (*wt.config.Test.Pickers.CorrelationPicker.GetByLocator(&resMap)).(string)
+
+	failures := wt.checkExpectations(correlationId, cb.Header, resMap)
+
+	deliveryID, hasDeliveryID := "", false
+	if deliveryPicker := wt.config.Test.Pickers.DeliveryIDPicker; deliveryPicker.Path != "" {
+		deliveryID, hasDeliveryID = pick(deliveryPicker, cb.Header, resMap)
 	}
-	_tracker := wt.internal.reqTracker.Get(correlationId)
-	_tracker.EndTime = time.Now()
 
-	slog.Debug("Updating tracker", "key", correlationId, "value", _tracker)
-	wt.internal.reqTracker.Set(correlationId, _tracker)
-	wt.internal.requestWg.Done()
+	slog.Debug("Updating tracker", "key", correlationId, "endTime", endTime)
+	var response types.ResponseRule
+	completed := false
+	found := wt.internal.reqTracker.Update(correlationId, func(r *tracker.RequestTrackerPair) {
+		if signatureErr != nil {
+			response = types.ResponseRule{Status: http.StatusUnauthorized, Body: signatureErr.Error()}
+		} else if cb.Reply == nil {
+			// nobody to reply to, eg. a broker message, so reply rules
+			// can't make the sender retry
+			response = defaultResponse
+		} else {
+			response = pickResponse(wt.config.Receiver.Responses, len(r.Attempts)+1, wt.internal.responseRand)
+		}
+
+		status := response.Status
+		if response.Hang {
+			status = 0
+		}
+		r.Attempts = append(r.Attempts, tracker.Attempt{Time: endTime, Status: status})
+
+		// the request is done once a callback is accepted, or once it is
+		// known to be wrongly signed
+		if accepts(response) || signatureErr != nil {
+			if r.EndTime.IsZero() {
+				r.EndTime = endTime
+				r.CallbackBytes = len(bytedata)
+				// skipped requests and failed sends were already given up on
+				completed = !r.SendFailed && !r.Skipped
+				// late, but it did come
+				if r.Error == tracker.NoCallbackError {
+					r.Error = ""
+				}
+			} else {
+				r.Redeliveries = append(r.Redeliveries, endTime)
+				// without a delivery id every redelivery is a duplicate
+				if !hasDeliveryID || slices.Contains(r.DeliveryIDs, deliveryID) {
+					r.Duplicates++
+				}
+			}
+			if hasDeliveryID && !slices.Contains(r.DeliveryIDs, deliveryID) {
+				r.DeliveryIDs = append(r.DeliveryIDs, deliveryID)
+			}
+		}
+		if signatureErr != nil {
+			r.InvalidSignature = true
+			r.Error = signatureErr.Error()
+		}
+		if len(failures) > 0 {
+			r.AssertionFailures = append(r.AssertionFailures, failures...)
+			if r.Error == "" {
+				r.Error = "assertion failed: " + failures[0].Rule + ": " + failures[0].Message
+			}
+		}
+	})
+	if !found {
+		wt.recordOrphan(cb, tracker.OrphanUnknownCorrelationID)
+		return
+	}
+	// retries of an already completed request must not complete it twice
+	if completed {
+		wt.internal.requestWg.Done()
+	}
+
+	if cb.Reply != nil {
+		cb.Reply(response)
+	}
+	wt.archiveCallback(correlationId, cb, response, "")
 }
 
 // FireRequests implements WebhookTesterv2.
@@ -72,105 +167,179 @@ func (wt *DefaultWebhookTester) FireRequests() error {
 	serverURL := <-wt.internal.selfUrlChan
 	slog.Debug("Server ready", "addr", serverURL)
 
+	arrival := wt.internal.arrival
+	if arrival == nil {
+		arrival, _ = scheduler.NewArrival(types.ArrivalConfig{}, wt.internal.iterGap)
+	}
+
+	if wt.internal.trigger == nil {
+		trigger, err := newTrigger(wt.config)
+		if err != nil {
+			return err
+		}
+		wt.internal.trigger = trigger
+	}
+
+	pool := scheduler.NewPool(wt.config.Run.MaxInFlight, wt.config.Run.InFlightPolicy)
+	defer pool.Close()
+	wt.internal.pool = pool
+
+	// requests are scheduled against absolute times so that time spent
+	// firing does not slowly drift the run away from the configured rate
+	next := time.Now()
+	skipped := 0
 	for i := 0; i < wt.config.Run.Iterations; i++ {
+		if wait := time.Until(next); wait > 0 {
+			slog.Debug("Going to sleep", "for", wait)
+			time.Sleep(wait)
+			slog.Debug("Woke up")
+		}
+
 		correlationId := uuid.New().String()
+		if i < len(wt.internal.correlationIds) {
+			correlationId = wt.internal.correlationIds[i]
+		}
+		scheduledTime := next
 
-		wt.internal.reqTracker.Set(correlationId, tracker.RequestTrackerPair{
-			StartTime: time.Now(),
-		})
+		accepted := pool.Submit(func() {
+			wt.internal.reqTracker.Set(correlationId, tracker.RequestTrackerPair{
+				ScheduledTime: scheduledTime,
+				StartTime:     time.Now(),
+				Scenario:      wt.config.Test.Name,
+				Tags:          wt.config.Test.Tags,
+			})
 
-		go func() error {
-			var tmp map[string]any
-			reqBodyBytes := []byte(wt.config.Test.Body)
-			err := json.Unmarshal(reqBodyBytes, &tmp)
-			if err != nil {
+			if err := wt.fireRequest(correlationId); err != nil {
 				slog.Error("Failed to call api", "err", err)
-				return err
+				// the callback may have beaten the error, only stop waiting
+				// when it didn't
+				pending := false
+				wt.internal.reqTracker.Update(correlationId, func(r *tracker.RequestTrackerPair) {
+					r.SendFailed = true
+					r.Error = "trigger failed: " + err.Error()
+					pending = r.EndTime.IsZero()
+				})
+				if pending {
+					wt.internal.requestWg.Done()
+				}
 			}
+		})
 
-			injectors := wt.config.Test.Injectors
+		if !accepted {
+			slog.Debug("Too many requests in flight, skipping", "key", correlationId)
+			wt.internal.reqTracker.Set(correlationId, tracker.RequestTrackerPair{
+				ScheduledTime: scheduledTime,
+				Skipped:       true,
+				Scenario:      wt.config.Test.Name,
+				Tags:          wt.config.Test.Tags,
+			})
+			wt.internal.requestWg.Done()
+			skipped++
+		}
 
-			if injectors.CorrelationIDInjector.GetRootType() == types.RootBody {
-				slog.Debug("Setting correlationId to body", "key", injectors.CorrelationIDInjector.GetKey())
-				injectors.CorrelationIDInjector.SetToLocator(
-					&tmp,
-					correlationId,
-				)
-			}
+		next = next.Add(arrival.Next())
+	}
+	slog.Info("Requests fired...", "skipped", skipped)
+	return nil
+}
 
-			if injectors.ReplyPathInjector.GetRootType() == types.RootBody {
-				slog.Debug("Setting replyPath to body", "key", injectors.ReplyPathInjector.GetKey())
-				injectors.ReplyPathInjector.SetToLocator(
-					&tmp,
-					wt.internal.selfUrl,
-				)
-			}
+// fireRequest builds the templated trigger for correlationId and sends it
+// to the api under test.
+func (wt *DefaultWebhookTester) fireRequest(correlationId string) error {
+	msg, err := wt.buildMessage(correlationId)
+	if err != nil {
+		return err
+	}
 
-			reqBodyBytes, err = json.Marshal(tmp)
-			if err != nil {
-				slog.Error("Failed to create json from interface", "err", err)
-				return err
-			}
+	payload, err := msg.Payload()
+	if err != nil {
+		return err
+	}
 
-			req, err := http.NewRequest(
-				http.MethodPost,
-				wt.config.Test.URL,
-				bytes.NewReader(reqBodyBytes),
-			)
+	started := time.Now()
+	res, err := wt.internal.trigger.Fire(context.Background(), msg)
+	sent := time.Now()
+	wt.archiveTrigger(msg, started, res, err)
+	wt.internal.reqTracker.Update(correlationId, func(r *tracker.RequestTrackerPair) {
+		r.SendEndTime = sent
+		r.RequestBytes = len(payload)
+		if res != nil {
+			r.StatusCode = res.Status
+			r.ResponseBytes = len(res.Body)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if res != nil {
+		slog.Debug("Request Sent", "key", correlationId, "status", res.Status, "resBody", res.Body)
+	}
+	return nil
+}
 
-			if err != nil {
-				return err
-			}
+// buildMessage templates the trigger for correlationId. Triggers only
+// differ by their injected values, so the same trigger can be rebuilt
+// later on, eg. to compare callbacks against it.
+func (wt *DefaultWebhookTester) buildMessage(correlationId string) (*TriggerMessage, error) {
+	var tmp map[string]any
+	if triggerType(wt.config) == TriggerGraphQL {
+		tmp = graphqlRequest(wt.config.Test)
+	} else if err := json.Unmarshal([]byte(wt.config.Test.Body), &tmp); err != nil {
+		return nil, err
+	}
 
-			// Add Test related custom headers
-			customheaders := wt.config.Test.Headers
-			if len(wt.config.Test.Headers) != 0 {
-				for k, v := range customheaders {
-					req.Header.Add(k, v)
-				}
-			}
+	injectors := wt.config.Test.Injectors
 
-			if injectors.CorrelationIDInjector.GetRootType() == types.RootHeader {
-				slog.Debug("Setting correlation to header")
-				req.Header.Add(
-					injectors.CorrelationIDInjector.GetKey(),
-					correlationId,
-				)
-			}
+	if injectors.CorrelationIDInjector.GetRootType() == types.RootBody {
+		slog.Debug("Setting correlationId to body", "key", injectors.CorrelationIDInjector.GetKey())
+		injectors.CorrelationIDInjector.SetToLocator(
+			&tmp,
+			correlationId,
+		)
+	}
 
-			if injectors.ReplyPathInjector.GetRootType() == types.RootHeader {
-				slog.Debug(
-					"Setting replyPath to header",
-					"key", injectors.ReplyPathInjector.GetKey(),
-				)
-				req.Header.Add(injectors.ReplyPathInjector.GetKey(), wt.internal.selfUrl)
-			}
+	if injectors.ReplyPathInjector.GetRootType() == types.RootBody {
+		slog.Debug("Setting replyPath to body", "key", injectors.ReplyPathInjector.GetKey())
+		injectors.ReplyPathInjector.SetToLocator(
+			&tmp,
+			wt.internal.selfUrl,
+		)
+	}
 
-			res, err := http.DefaultClient.Do(req)
-			if err != nil {
-				return err
-			}
+	header := http.Header{}
 
-			resBody, err := io.ReadAll(res.Body)
-			if err != nil {
-				return err
-			}
-			slog.Debug("Request Sent", "req", req, "resBody", resBody)
-			return nil
-		}()
+	// Add Test related custom headers
+	for k, v := range wt.config.Test.Headers {
+		header.Add(k, v)
+	}
 
-		slog.Debug("Going to sleep")
-		time.Sleep(wt.internal.iterGap)
-		slog.Debug("Woke up")
+	if injectors.CorrelationIDInjector.GetRootType() == types.RootHeader {
+		slog.Debug("Setting correlation to header")
+		header.Add(
+			injectors.CorrelationIDInjector.GetKey(),
+			correlationId,
+		)
 	}
-	slog.Info("Requests fired...")
-	return nil
+
+	if injectors.ReplyPathInjector.GetRootType() == types.RootHeader {
+		slog.Debug(
+			"Setting replyPath to header",
+			"key", injectors.ReplyPathInjector.GetKey(),
+		)
+		header.Add(injectors.ReplyPathInjector.GetKey(), wt.internal.selfUrl)
+	}
+
+	return &TriggerMessage{CorrelationID: correlationId, Header: header, Body: tmp}, nil
 }
 
 // LoadConfig implements WebhookTesterv2.
 // validate and throw results
 func (wt *DefaultWebhookTester) LoadConfig() error {
 	slog.Info("Loading and validating config...")
+	if triggerType(wt.config) == TriggerGraphQL {
+		resolveVariableLocators(&wt.config.Test)
+	}
+
 	injectors := wt.config.Test.Injectors
 	if corrRootType := injectors.CorrelationIDInjector.GetRootType(); corrRootType == types.RootUnknown {
 		return errors.New("Unknown root type: " + injectors.CorrelationIDInjector.GetRootTypeString())
@@ -183,125 +352,129 @@ func (wt *DefaultWebhookTester) LoadConfig() error {
 	if corrPickerRt := pickers.CorrelationPicker.GetRootType(); corrPickerRt == types.RootUnknown {
 		return errors.New("Unknown root type: " + pickers.CorrelationPicker.GetRootTypeString())
 	}
+	if deliveryPicker := pickers.DeliveryIDPicker; deliveryPicker.Path != "" && deliveryPicker.GetRootType() == types.RootUnknown {
+		return errors.New("Unknown root type: " + deliveryPicker.GetRootTypeString())
+	}
 
-	return nil
-}
-
-// PostProcess implements WebhookTesterv2.
-func (wt *DefaultWebhookTester) PostProcess() error {
-	allReqs := wt.internal.reqTracker.GetAll()
-	tp := []tracker.RequestTrackerPair{}
-	for _, v := range allReqs {
-		tp = append(tp, v)
+	if _, err := triggerFactory(wt.config); err != nil {
+		return err
 	}
 
-	metrics := reporter.CalculateMetrics(tp, time.Duration(wt.config.Run.DurationSeconds)*time.Second)
+	if err := scheduler.ValidatePolicy(wt.config.Run.InFlightPolicy); err != nil {
+		return err
+	}
 
-	for _, output := range wt.config.Outputs {
-		switch output.Type {
-		case "text":
-			w, err := createFileWithParentDirs(output.Path)
-			defer w.Close()
-			if err != nil {
-				return err
-			}
-			reporter.PrintTextMetrics(w, metrics)
-		case "stdout":
-			reporter.PrintTextMetrics(os.Stdout, metrics)
-		default:
-			return types.UnsupportedOutputErr
-		}
+	arrival, err := scheduler.NewArrival(wt.config.Run.Arrival, wt.internal.iterGap)
+	if err != nil {
+		return err
 	}
+	wt.internal.arrival = arrival
 
-	return nil
-}
+	wt.internal.responseRand = newResponseRand(wt.config.Receiver.Seed)
 
-func (wt *DefaultWebhookTester) startHttpServer() (context.CancelFunc, error) {
-	mux := http.NewServeMux()
-	mux.Handle("/", http.HandlerFunc(wt.receiverHandler))
+	signatureScheme, err := signature.New(wt.config.Receiver.Signature)
+	if err != nil {
+		return err
+	}
+	wt.internal.signature = signatureScheme
 
-	server := &http.Server{Addr: ":8081", Handler: mux}
-	wt.internal.selfUrl = "http://localhost:8081/"
+	expect, err := assertions.Compile(wt.config.Test.Expect)
+	if err != nil {
+		return err
+	}
+	wt.internal.expect = expect
 
-	cancelContext, stopReceiver := context.WithCancel(context.Background())
-	go func() {
-		<-cancelContext.Done()
+	archiveWriter, err := archive.New(wt.config.Archive)
+	if err != nil {
+		return err
+	}
+	wt.internal.archive = archiveWriter
 
-		slog.Info("Shutting down receiver...")
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer shutdownCancel()
+	if err := validateOutputs(wt.config.Outputs); err != nil {
+		return err
+	}
+	if err := reporter.ValidateThresholds(wt.config.Report.Thresholds); err != nil {
+		return err
+	}
 
-		if err := server.Shutdown(shutdownCtx); err != nil {
-			slog.Error("Error shutting down server", "err", err)
+	if store := wt.config.Tracker.Store; store != "" && store != tracker.StoreMemory {
+		reqTracker, err := openTrackerStore(wt.config)
+		if err != nil {
+			return err
 		}
-	}()
+		wt.internal.reqTracker = reqTracker
+	}
 
-	go func() {
-		if err := server.ListenAndServe(); err != nil {
-			slog.Error("Error shutting down server", "err", err)
+	return nil
+}
+
+// PostProcess implements WebhookTesterv2.
+func (wt *DefaultWebhookTester) PostProcess() error {
+	if err := wt.Close(); err != nil {
+		slog.Error("Failed to close trigger and archive", "err", err)
+	}
+
+	defer func() {
+		if err := wt.internal.reqTracker.Close(); err != nil {
+			slog.Error("Failed to close tracker store", "err", err)
 		}
 	}()
 
-	wt.internal.selfUrl = "http://localhost:8081/"
-	wt.internal.selfUrlChan <- "http://localhost:8081/"
-	slog.Info("Initialised receiver...")
-	return stopReceiver, nil
+	metrics, err := storeMetrics(wt.internal.reqTracker, wt.config)
+	if err != nil {
+		return err
+	}
+	if r, ok := wt.internal.receiver.(DisconnectionReporter); ok {
+		metrics.Disconnections = reporter.CalculateDisconnectionMetrics(r.Disconnections())
+	}
+
+	metrics.Thresholds = reporter.EvaluateThresholds(metrics, wt.config.Report.Thresholds)
+
+	return WriteOutputs(wt.config.Outputs, reporter.Report{
+		Metrics: metrics,
+		Records: wt.internal.reqTracker,
+		Config:  wt.config,
+	})
 }
 
-func (wt *DefaultWebhookTester) startNgrokServer() (context.CancelFunc, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+// StartReceiver implements WebhookTesterv2.
+func (wt *DefaultWebhookTester) StartReceiver() (context.CancelFunc, error) {
+	receiver, err := newReceiver(wt.config)
+	if err != nil {
+		return func() {}, err
+	}
+	return wt.UseCustomReceiver(receiver)
+}
 
-	if _, found := os.LookupEnv("NGROK_AUTHTOKEN"); !found {
-		return cancel, types.NgrokAuthMissingErr
+// UseCustomReceiver starts receiver and handles the callbacks it picks up,
+// it replaces StartReceiver for backends built outside of the registry.
+func (wt *DefaultWebhookTester) UseCustomReceiver(receiver Receiver) (context.CancelFunc, error) {
+	if err := receiver.Start(context.Background()); err != nil {
+		return func() {}, err
 	}
+	wt.internal.receiver = receiver
 
 	go func() {
-		// Handle system interrupts and termination signals
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		defer signal.Stop(sigCh)
-
-		// Initialize the ngrok listener
-		listener, err := ngrok.Listen(ctx,
-			config.HTTPEndpoint(),
-			ngrok.WithAuthtokenFromEnv(),
-		)
-		if err != nil {
-			slog.Error("Error setting up ngrok:", "error", err)
-			os.Exit(1)
-			return
-		}
-
-		slog.Debug("Ingress established at:", "addr", listener.URL())
-		wt.internal.selfUrl = listener.URL()
-		wt.internal.selfUrlChan <- listener.URL()
-		// Start HTTP server
-		err = http.Serve(listener, http.HandlerFunc(wt.receiverHandler))
-		if err != nil {
-			log.Println("HTTP server error:", err)
-		}
-
-		// Listen for system interrupts
-		select {
-		case <-sigCh:
-			log.Println("Received shutdown signal")
-		case <-ctx.Done():
-			log.Println("Context cancelled")
+		for cb := range receiver.Callbacks() {
+			// replies may be held back on purpose, so callbacks are
+			// handled side by side
+			go wt.handleCallback(cb)
 		}
 	}()
 
-	slog.Info("Initialised receiver...")
-	return cancel, nil
-}
+	selfUrl := receiver.URL()
+	wt.internal.selfUrl = selfUrl
+	wt.internal.selfUrlChan <- selfUrl
 
-// StartReceiver implements WebhookTesterv2.
-func (wt *DefaultWebhookTester) StartReceiver() (cancelFunc context.CancelFunc, err error) {
-	if wt.config.Server == "ngrok" {
-		cancelFunc, err = wt.startNgrokServer()
-	} else {
-		cancelFunc, err = wt.startHttpServer()
-	}
-	return cancelFunc, err
+	return func() {
+		slog.Info("Shutting down receiver...")
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+
+		if err := receiver.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Error shutting down receiver", "err", err)
+		}
+	}, nil
 }
 
 // WaitForResults implements WebhookTesterv2.
@@ -317,12 +490,36 @@ func (wt *DefaultWebhookTester) WaitForResults() error {
 	case <-waitingFinished:
 		slog.Info("Finished waiting within timeout")
 	case <-time.After(timeout):
-		slog.Error("Timed out while waiting for 2mins")
+		slog.Error("Timed out while waiting for results", "timeout", timeout)
+		wt.markTimedOut()
 		return types.TimedOutWaitingForResultsErr
 	}
 	return nil
 }
 
+// markTimedOut gives the requests still waiting for a callback an error, so
+// reports say why they failed.
+func (wt *DefaultWebhookTester) markTimedOut() {
+	var pending []string
+	err := wt.internal.reqTracker.Range(func(key string, value tracker.RequestTrackerPair) bool {
+		if value.Outcome() == tracker.OutcomeTimedOut && value.Error == "" {
+			pending = append(pending, key)
+		}
+		return true
+	})
+	if err != nil {
+		slog.Error("Failed to read records", "err", err)
+	}
+	// updated once ranging is over, stores may not be written while read
+	for _, key := range pending {
+		wt.internal.reqTracker.Update(key, func(r *tracker.RequestTrackerPair) {
+			if r.Outcome() == tracker.OutcomeTimedOut && r.Error == "" {
+				r.Error = tracker.NoCallbackError
+			}
+		})
+	}
+}
+
 func NewDefaultWebhookTester(config *types.InputConfig) *DefaultWebhookTester {
 	wt := &DefaultWebhookTester{
 		config: config,
@@ -333,7 +530,7 @@ func NewDefaultWebhookTester(config *types.InputConfig) *DefaultWebhookTester {
 }
 
 func (wt2 *DefaultWebhookTester) setup() {
-	iterGap := time.Duration((wt2.config.Run.DurationSeconds*1000)/wt2.config.Run.Iterations) * time.Millisecond
+	iterGap := scheduler.MeanGap(wt2.config.Run.Iterations, time.Duration(wt2.config.Run.DurationSeconds)*time.Second)
 	wt2.internal = &internalConfig{
 		iterGap:       iterGap,
 		requestWg:     sync.WaitGroup{},
@@ -346,6 +543,13 @@ func (wt2 *DefaultWebhookTester) setup() {
 		wt2.config.Test.Timeout = int(DEFAULT_WAITING_TIMEOUT.Seconds())
 	}
 
+	if wt2.config.Receiver.Listen == "" {
+		wt2.config.Receiver.Listen = DEFAULT_RECEIVER_LISTEN
+	}
+	if !strings.HasPrefix(wt2.config.Receiver.Path, "/") {
+		wt2.config.Receiver.Path = "/" + wt2.config.Receiver.Path
+	}
+
 	configStr, _ := json.MarshalIndent(wt2.config, "", "  ")
 	slog.Debug(string(configStr))
 }
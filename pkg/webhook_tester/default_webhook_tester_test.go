@@ -0,0 +1,182 @@
+package webhook_tester
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/scheduler"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// fireAt sends 3 requests to target and waits for their results, which
+// must not take the whole timeout.
+func fireAt(t *testing.T, target string) *DefaultWebhookTester {
+	var config types.InputConfig
+	config.Test.URL = target
+	config.Test.Body = `{"kind": "resize"}`
+	config.Test.Injectors.CorrelationIDInjector.Path = "body.uniqueId"
+	config.Test.Injectors.ReplyPathInjector.Path = "headers.reply-to"
+	config.Test.Pickers.CorrelationPicker.Path = "body.uniqueId"
+	config.Test.Timeout = 30
+	config.Receiver.Listen = "127.0.0.1:0"
+	config.Run.Iterations = 3
+
+	wt := NewDefaultWebhookTester(&config)
+	if err := wt.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	t.Cleanup(stop)
+	if err := wt.FireRequests(); err != nil {
+		t.Fatalf("FireRequests failed: %v", err)
+	}
+
+	started := time.Now()
+	if err := wt.WaitForResults(); err != nil {
+		t.Fatalf("Expected failed sends not to be waited for, got %v", err)
+	}
+	if waited := time.Since(started); waited > 5*time.Second {
+		t.Errorf("Expected WaitForResults to return promptly, waited %s", waited)
+	}
+	wt.WaitForRequests()
+	return wt
+}
+
+func TestWaitForResults_FailedSendsDontBlock(t *testing.T) {
+	// a target that refuses every connection
+	target := httptest.NewServer(nil)
+	target.Close()
+
+	wt := fireAt(t, target.URL)
+	for id, record := range wt.Records() {
+		if record.Outcome() != tracker.OutcomeTriggerFailed {
+			t.Errorf("%s: expected the send to fail, got %s", id, record.Outcome())
+		}
+	}
+}
+
+func TestWaitForResults_ErrorAnswersFailTrigger(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer target.Close()
+
+	wt := fireAt(t, target.URL)
+	for id, record := range wt.Records() {
+		if record.Outcome() != tracker.OutcomeTriggerFailed || record.StatusCode != http.StatusInternalServerError {
+			t.Errorf("%s: expected the 500 to fail the trigger, got %s with %d", id, record.Outcome(), record.StatusCode)
+		}
+		if !strings.Contains(record.Error, "500 Internal Server Error") {
+			t.Errorf("%s: expected the status in the error, got %q", id, record.Error)
+		}
+	}
+}
+
+func TestWaitForResults_MarksTimedOutRequests(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	wt.config.Test.Timeout = 0
+	if err := wt.WaitForResults(); err == nil {
+		t.Fatal("Expected the pending request to time out")
+	}
+	if record := wt.Records()["req-1"]; record.Outcome() != tracker.OutcomeTimedOut || record.Error != tracker.NoCallbackError {
+		t.Errorf("Expected the timed out request to say why, got %s: %q", record.Outcome(), record.Error)
+	}
+
+	deliver(wt, `{"uniqueId": "req-1"}`, nil)
+	if record := wt.Records()["req-1"]; record.Outcome() != tracker.OutcomeOK || record.Error != "" {
+		t.Errorf("Expected a late callback to clear the error, got %s: %q", record.Outcome(), record.Error)
+	}
+}
+
+func TestHandleCallback_SkippedRequestsAreNotCompletedTwice(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	// as FireRequests does when the pool drops the request
+	wt.internal.reqTracker.Update("req-1", func(r *tracker.RequestTrackerPair) {
+		r.Skipped = true
+	})
+	wt.internal.requestWg.Done()
+
+	// a stray callback with the id of the dropped request
+	deliver(wt, `{"uniqueId": "req-1"}`, nil)
+	if err := wt.WaitForResults(); err != nil {
+		t.Fatalf("Expected nothing left to wait for, got %v", err)
+	}
+	if record := wt.Records()["req-1"]; record.Outcome() != tracker.OutcomeSkipped {
+		t.Errorf("Expected the request to stay skipped, got %s", record.Outcome())
+	}
+}
+
+func TestFireRequests_BlockPolicyRecordsScheduleLag(t *testing.T) {
+	busy := 50 * time.Millisecond
+	// a slow target, calling back once it is done
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		time.Sleep(busy)
+		go http.Post(r.Header.Get("reply-to"), "application/json", bytes.NewReader(body))
+	}))
+	defer target.Close()
+
+	var config types.InputConfig
+	config.Test.URL = target.URL
+	config.Test.Body = `{"kind": "resize"}`
+	config.Test.Injectors.CorrelationIDInjector.Path = "body.uniqueId"
+	config.Test.Injectors.ReplyPathInjector.Path = "headers.reply-to"
+	config.Test.Pickers.CorrelationPicker.Path = "body.uniqueId"
+	config.Test.Timeout = 5
+	config.Receiver.Listen = "127.0.0.1:0"
+	// every request is due at once, but only one may be in flight
+	config.Run.Iterations = 3
+	config.Run.MaxInFlight = 1
+	config.Run.InFlightPolicy = scheduler.PolicyBlock
+
+	wt := NewDefaultWebhookTester(&config)
+	if err := wt.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	defer stop()
+	if err := wt.FireRequests(); err != nil {
+		t.Fatalf("FireRequests failed: %v", err)
+	}
+	if err := wt.WaitForResults(); err != nil {
+		t.Fatalf("Expected every request to be called back, got %v", err)
+	}
+	wt.WaitForRequests()
+
+	var pairs []tracker.RequestTrackerPair
+	for _, record := range wt.Records() {
+		pairs = append(pairs, record)
+	}
+	metrics := reporter.CalculateMetrics(pairs, time.Second)
+	if metrics.SkippedRequests != 0 || metrics.MaxScheduleLag < 2*busy*4/5 {
+		t.Errorf("Expected requests to queue up as schedule lag, got max lag %s and %d skipped", metrics.MaxScheduleLag, metrics.SkippedRequests)
+	}
+}
+
+func TestNewDefaultWebhookTester_MeanGap(t *testing.T) {
+	var config types.InputConfig
+	config.Run.DurationSeconds = 1
+	config.Run.Iterations = 4000
+	// past 1000 rps the gap is below a millisecond, but not 0
+	if gap := NewDefaultWebhookTester(&config).internal.iterGap; gap != 250*time.Microsecond {
+		t.Errorf("Expected a 250µs gap, got %s", gap)
+	}
+
+	config.Run.Iterations = 0
+	if gap := NewDefaultWebhookTester(&config).internal.iterGap; gap != 0 {
+		t.Errorf("Expected no gap without iterations, got %s", gap)
+	}
+}
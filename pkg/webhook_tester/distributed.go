@@ -0,0 +1,87 @@
+package webhook_tester
+
+import (
+	"log/slog"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+// The methods below let a distributed controller and its workers split a
+// run between them: the controller only runs the receiver while workers
+// only fire requests.
+
+// ReceiverURL blocks until the receiver is up and returns the url it
+// advertises to the api under test.
+func (wt *DefaultWebhookTester) ReceiverURL() string {
+	url := <-wt.internal.selfUrlChan
+	// put it back for FireRequests
+	wt.internal.selfUrlChan <- url
+	return url
+}
+
+// UseReceiver points injected reply paths at a receiver started elsewhere,
+// it replaces StartReceiver on workers.
+func (wt *DefaultWebhookTester) UseReceiver(url string) {
+	wt.internal.selfUrl = url
+	wt.internal.selfUrlChan <- url
+}
+
+// SetCorrelationIDs makes FireRequests use ids instead of generating them.
+func (wt *DefaultWebhookTester) SetCorrelationIDs(ids []string) {
+	wt.internal.correlationIds = ids
+}
+
+// Expect registers requests fired by someone else, so that WaitForResults
+// waits for their callbacks.
+func (wt *DefaultWebhookTester) Expect(ids []string) {
+	wt.internal.requestWg.Add(len(ids))
+	for _, id := range ids {
+		wt.internal.reqTracker.Set(id, tracker.RequestTrackerPair{})
+	}
+}
+
+// MergeRecords folds records shipped back by a worker into the tracker.
+// Callback times recorded by the local receiver are kept as they are.
+func (wt *DefaultWebhookTester) MergeRecords(records map[string]tracker.RequestTrackerPair) {
+	for id, record := range records {
+		pending := false
+		wt.internal.reqTracker.Update(id, func(r *tracker.RequestTrackerPair) {
+			pending = r.EndTime.IsZero() && !r.SendFailed && !r.Skipped
+			r.ScheduledTime = record.ScheduledTime
+			r.StartTime = record.StartTime
+			r.SendEndTime = record.SendEndTime
+			r.Skipped = record.Skipped
+			r.SendFailed = record.SendFailed
+			r.StatusCode = record.StatusCode
+			r.Scenario = record.Scenario
+			r.Tags = record.Tags
+			r.RequestBytes = record.RequestBytes
+			r.ResponseBytes = record.ResponseBytes
+			if r.Error == "" {
+				r.Error = record.Error
+			}
+		})
+		// no callback will ever come for skipped requests, nor is one
+		// waited for once sending failed
+		if (record.Skipped || record.SendFailed) && pending {
+			wt.internal.requestWg.Done()
+		}
+	}
+}
+
+// Records returns a snapshot of every request tracked so far.
+func (wt *DefaultWebhookTester) Records() map[string]tracker.RequestTrackerPair {
+	records, err := tracker.Snapshot(wt.internal.reqTracker)
+	if err != nil {
+		slog.Error("Failed to read records", "err", err)
+	}
+	return records
+}
+
+// WaitForRequests blocks until every api call made by FireRequests has
+// returned.
+func (wt *DefaultWebhookTester) WaitForRequests() {
+	if wt.internal.pool != nil {
+		wt.internal.pool.Wait()
+	}
+}
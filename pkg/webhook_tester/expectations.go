@@ -0,0 +1,36 @@
+package webhook_tester
+
+import (
+	"net/http"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/assertions"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+)
+
+// checkExpectations evaluates test.expect against a callback for
+// correlationId and returns the rules it broke.
+func (wt *DefaultWebhookTester) checkExpectations(correlationId string, header http.Header, body map[string]any) []tracker.AssertionFailure {
+	if len(wt.internal.expect) == 0 {
+		return nil
+	}
+
+	callback := assertions.Message{Header: header, Body: body}
+	failures := assertions.Evaluate(wt.internal.expect, callback, func() (*assertions.Message, error) {
+		return wt.originalRequest(correlationId)
+	})
+
+	result := make([]tracker.AssertionFailure, len(failures))
+	for i, f := range failures {
+		result[i] = tracker.AssertionFailure{Rule: f.Rule, Message: f.Message}
+	}
+	return result
+}
+
+// originalRequest rebuilds the request fired for correlationId.
+func (wt *DefaultWebhookTester) originalRequest(correlationId string) (*assertions.Message, error) {
+	msg, err := wt.buildMessage(correlationId)
+	if err != nil {
+		return nil, err
+	}
+	return &assertions.Message{Header: msg.Header, Body: msg.Body}, nil
+}
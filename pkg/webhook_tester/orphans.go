@@ -0,0 +1,59 @@
+package webhook_tester
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// pickCorrelationID finds the correlation id of a callback. When it can't,
+// the reason the callback is an orphan is returned instead.
+func (wt *DefaultWebhookTester) pickCorrelationID(header http.Header, body map[string]any, bodyErr error) (string, string) {
+	picker := wt.config.Test.Pickers.CorrelationPicker
+
+	switch picker.GetRootType() {
+	case types.RootHeader:
+		if id := header.Get(picker.GetKey()); id != "" {
+			return id, ""
+		}
+		return "", tracker.OrphanMissingCorrelationID
+	case types.RootBody:
+		if bodyErr != nil {
+			return "", tracker.OrphanMalformedBody
+		}
+		value := picker.GetByLocator(&body)
+		if value == nil || *value == nil {
+			return "", tracker.OrphanMissingCorrelationID
+		}
+		id, ok := (*value).(string)
+		if !ok {
+			return "", tracker.OrphanInvalidCorrelationID
+		}
+		return id, ""
+	}
+	return "", tracker.OrphanMissingCorrelationID
+}
+
+// recordOrphan keeps a callback that matches no request aside, without
+// touching completion counting.
+func (wt *DefaultWebhookTester) recordOrphan(cb *Callback, reason string) {
+	slog.Debug("Orphan callback", "reason", reason)
+	wt.internal.reqTracker.AddOrphan(tracker.Orphan{
+		Time:    cb.ReceivedAt,
+		Reason:  reason,
+		Headers: cb.Header.Clone(),
+		Body:    string(cb.Body),
+	})
+
+	status := wt.config.Receiver.OrphanStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	reply := types.ResponseRule{Status: status}
+	if cb.Reply != nil {
+		cb.Reply(reply)
+	}
+	wt.archiveCallback("", cb, reply, reason)
+}
@@ -0,0 +1,128 @@
+package webhook_tester
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	OutputText   = "text"
+	OutputStdout = "stdout"
+	OutputJSON   = "json"
+	OutputCSV    = "csv"
+	OutputJUnit  = "junit"
+	OutputHTML   = "html"
+)
+
+// Output writes the report of a run in one format.
+type Output interface {
+	Write(w io.Writer, report reporter.Report) error
+}
+
+// OutputFunc adapts a function to an Output.
+type OutputFunc func(w io.Writer, report reporter.Report) error
+
+func (f OutputFunc) Write(w io.Writer, report reporter.Report) error {
+	return f(w, report)
+}
+
+var (
+	outputsLock sync.RWMutex
+	outputs     = map[string]Output{}
+)
+
+// RegisterOutput makes a format available as outputs[].type name,
+// registering a name twice replaces the earlier format.
+func RegisterOutput(name string, output Output) {
+	outputsLock.Lock()
+	defer outputsLock.Unlock()
+	outputs[name] = output
+}
+
+// Outputs lists the names of every registered format.
+func Outputs() []string {
+	outputsLock.RLock()
+	defer outputsLock.RUnlock()
+
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupOutput(name string) (Output, error) {
+	outputsLock.RLock()
+	defer outputsLock.RUnlock()
+
+	output, found := outputs[name]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", types.UnsupportedOutputErr, name)
+	}
+	return output, nil
+}
+
+func init() {
+	text := OutputFunc(func(w io.Writer, report reporter.Report) error {
+		reporter.PrintTextMetrics(w, report.Metrics)
+		return nil
+	})
+	RegisterOutput(OutputText, text)
+	RegisterOutput(OutputStdout, text)
+	RegisterOutput(OutputJSON, OutputFunc(func(w io.Writer, report reporter.Report) error {
+		return reporter.WriteJSONMetrics(w, report.Metrics)
+	}))
+	RegisterOutput(OutputCSV, OutputFunc(func(w io.Writer, report reporter.Report) error {
+		return reporter.WriteCSVRecords(w, report.Records)
+	}))
+	RegisterOutput(OutputJUnit, OutputFunc(reporter.WriteJUnitReport))
+	RegisterOutput(OutputHTML, OutputFunc(reporter.WriteHTMLReport))
+}
+
+// validateOutputs checks every output is of a registered format, so a typo
+// doesn't only show once the run is over.
+func validateOutputs(configs []types.OutputConfig) error {
+	for _, config := range configs {
+		if _, err := lookupOutput(config.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteOutputs writes report to every configured output. Outputs without a
+// path, and stdout, are written to standard output.
+func WriteOutputs(configs []types.OutputConfig, report reporter.Report) error {
+	for _, config := range configs {
+		output, err := lookupOutput(config.Type)
+		if err != nil {
+			return err
+		}
+		if err := writeOutput(output, config, report); err != nil {
+			return fmt.Errorf("failed to write %s output: %w", config.Type, err)
+		}
+	}
+	return nil
+}
+
+func writeOutput(output Output, config types.OutputConfig, report reporter.Report) error {
+	if config.Type == OutputStdout || config.Path == "" {
+		return output.Write(os.Stdout, report)
+	}
+	w, err := createFileWithParentDirs(config.Path)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := output.Write(w, report); err != nil {
+		return err
+	}
+	return w.Close()
+}
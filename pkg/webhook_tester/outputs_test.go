@@ -0,0 +1,106 @@
+package webhook_tester
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func TestWriteOutputs_Formats(t *testing.T) {
+	dir := t.TempDir()
+	config := loadGraphQLConfig(t)
+	fast, impossible := 5000.0, 0.0
+	config.Report.Thresholds = []types.Threshold{
+		{Metric: "p95ResponseTime", Max: &fast},
+		{Metric: "maxResponseTime", Max: &impossible},
+	}
+	config.Outputs = []types.OutputConfig{
+		{Type: OutputJSON, Path: filepath.Join(dir, "metrics.json")},
+		{Type: OutputCSV, Path: filepath.Join(dir, "records.csv")},
+		{Type: OutputJUnit, Path: filepath.Join(dir, "junit.xml")},
+		{Type: OutputHTML, Path: filepath.Join(dir, "report.html")},
+	}
+
+	wt, err := runGraphQLTrigger(t, config)
+	if err != nil {
+		t.Fatalf("Expected every operation to be called back, got %v", err)
+	}
+	wt.WaitForRequests()
+	if err := wt.PostProcess(); err != nil {
+		t.Fatalf("PostProcess failed: %v", err)
+	}
+
+	var metrics reporter.Metrics
+	raw, _ := os.ReadFile(filepath.Join(dir, "metrics.json"))
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		t.Fatalf("Invalid json output: %v", err)
+	}
+	if metrics.TotalRequests != 5 || len(metrics.Thresholds) != 2 || !metrics.Thresholds[0].Passed || metrics.Thresholds[1].Passed {
+		t.Errorf("Unexpected json metrics %+v", metrics)
+	}
+
+	f, _ := os.Open(filepath.Join(dir, "records.csv"))
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid csv output: %v", err)
+	}
+	if len(rows) != 6 || rows[0][0] != "id" {
+		t.Fatalf("Expected a header and 5 records, got %v", rows)
+	}
+	for _, row := range rows[1:] {
+		if row[1] != "start-job" || row[2] != "ok" || row[8] == "" || row[19] != "env=test" {
+			t.Errorf("Unexpected record %v", row)
+		}
+	}
+
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string    `xml:"name,attr"`
+				Failure *struct{} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	raw, _ = os.ReadFile(filepath.Join(dir, "junit.xml"))
+	if err := xml.Unmarshal(raw, &suites); err != nil {
+		t.Fatalf("Invalid junit output: %v", err)
+	}
+	if suites.Tests != 2 || suites.Failures != 1 || suites.Suites[0].Name != "start-job" {
+		t.Fatalf("Expected one of two thresholds to fail, got\n%s", raw)
+	}
+	if cases := suites.Suites[0].Cases; cases[0].Name != "p95ResponseTime <= 5000" || cases[0].Failure != nil || cases[1].Failure == nil {
+		t.Errorf("Unexpected test cases %+v", cases)
+	}
+
+	raw, _ = os.ReadFile(filepath.Join(dir, "report.html"))
+	if page := string(raw); !strings.Contains(page, "<title>Webhook load test report: start-job</title>") || !strings.Contains(page, "<svg") {
+		t.Errorf("Expected an html report with charts, got\n%s", page)
+	}
+}
+
+func TestLoadConfig_RejectsUnknownOutputsAndThresholds(t *testing.T) {
+	config := loadGraphQLConfig(t)
+	config.Outputs = []types.OutputConfig{{Type: "pdf"}}
+	if err := NewDefaultWebhookTester(config).LoadConfig(); !errors.Is(err, types.UnsupportedOutputErr) {
+		t.Errorf("Expected the unknown output to be refused, got %v", err)
+	}
+
+	config = loadGraphQLConfig(t)
+	one := 1.0
+	config.Report.Thresholds = []types.Threshold{{Metric: "p99ResponseTime", Max: &one}}
+	if err := NewDefaultWebhookTester(config).LoadConfig(); err == nil || !strings.Contains(err.Error(), "Unknown threshold metric") {
+		t.Errorf("Expected the unknown metric to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package webhook_tester
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// pick reads the value pointed at by locator from the headers or the
+// decoded body of a callback. Non string values are formatted as is.
+func pick(locator types.Locator, header http.Header, body map[string]any) (string, bool) {
+	switch locator.GetRootType() {
+	case types.RootHeader:
+		value := header.Get(locator.GetKey())
+		return value, value != ""
+	case types.RootBody:
+		if body == nil {
+			return "", false
+		}
+		value := locator.GetByLocator(&body)
+		if value == nil || *value == nil {
+			return "", false
+		}
+		if s, ok := (*value).(string); ok {
+			return s, true
+		}
+		return fmt.Sprint(*value), true
+	}
+	return "", false
+}
@@ -0,0 +1,148 @@
+package webhook_tester
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	ReceiverHTTP  = "http"
+	ReceiverHTTPS = "https"
+	ReceiverUnix  = "unix"
+	ReceiverNgrok = "ngrok"
+)
+
+// Callback is a completion message picked up by a Receiver.
+type Callback struct {
+	Header     http.Header
+	Body       []byte
+	ReceivedAt time.Time
+	// Method and Source describe where the callback came from, eg. the
+	// request method and url, or a broker subject
+	Method string
+	Source string
+	// Context is done once the sender gave up on the callback
+	Context context.Context
+	// Reply answers the sender, it is nil for backends that can't. It must
+	// be called at most once.
+	Reply func(types.ResponseRule)
+}
+
+// Receiver is a backend callbacks arrive through.
+type Receiver interface {
+	// Start begins accepting callbacks, URL is known once it returns.
+	Start(ctx context.Context) error
+	// URL is the address advertised to the api under test.
+	URL() string
+	// Callbacks streams received callbacks, it is closed by Shutdown.
+	Callbacks() <-chan *Callback
+	// Shutdown stops accepting callbacks.
+	Shutdown(ctx context.Context) error
+}
+
+// ReceiverFactory builds a receiver from the run's config.
+type ReceiverFactory func(config *types.InputConfig) (Receiver, error)
+
+var (
+	receiversLock sync.RWMutex
+	receivers     = map[string]ReceiverFactory{}
+)
+
+// RegisterReceiver makes a backend available as receiver.type name,
+// registering a name twice replaces the earlier backend.
+func RegisterReceiver(name string, factory ReceiverFactory) {
+	receiversLock.Lock()
+	defer receiversLock.Unlock()
+	receivers[name] = factory
+}
+
+// Receivers lists the names of every registered backend.
+func Receivers() []string {
+	receiversLock.RLock()
+	defer receiversLock.RUnlock()
+
+	names := make([]string, 0, len(receivers))
+	for name := range receivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterReceiver(ReceiverHTTP, newHTTPReceiver)
+	RegisterReceiver(ReceiverHTTPS, newHTTPReceiver)
+	RegisterReceiver(ReceiverUnix, newHTTPReceiver)
+	RegisterReceiver(ReceiverNgrok, newNgrokReceiver)
+}
+
+// receiverType resolves which backend config asks for.
+func receiverType(config *types.InputConfig) string {
+	switch {
+	case config.Receiver.Type != "":
+		return config.Receiver.Type
+	case config.Server == ReceiverNgrok:
+		return ReceiverNgrok
+	case config.Receiver.TLS.Enabled:
+		return ReceiverHTTPS
+	}
+	return ReceiverHTTP
+}
+
+// newReceiver builds the backend config asks for.
+func newReceiver(config *types.InputConfig) (Receiver, error) {
+	name := receiverType(config)
+
+	receiversLock.RLock()
+	factory, found := receivers[name]
+	receiversLock.RUnlock()
+	if !found {
+		return nil, errors.New("Unknown receiver type: " + name + ", expected one of " + strings.Join(Receivers(), ", "))
+	}
+	return factory(config)
+}
+
+// callbackStream is the channel side of a Receiver. Nothing is sent on it
+// once it is closed, even by senders that were already waiting.
+type callbackStream struct {
+	lock   sync.RWMutex
+	ch     chan *Callback
+	closed bool
+}
+
+func newCallbackStream() *callbackStream {
+	return &callbackStream{ch: make(chan *Callback)}
+}
+
+// send hands cb to whoever reads the stream and reports whether it did.
+func (s *callbackStream) send(cb *Callback) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.closed {
+		return false
+	}
+	select {
+	case s.ch <- cb:
+		return true
+	case <-cb.Context.Done():
+		return false
+	}
+}
+
+func (s *callbackStream) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
@@ -0,0 +1,86 @@
+package webhook_tester
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/broker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func init() {
+	RegisterReceiver(broker.KindNATS, newBrokerReceiver)
+	RegisterReceiver(broker.KindKafka, newBrokerReceiver)
+	RegisterReceiver(broker.KindRedis, newBrokerReceiver)
+}
+
+// brokerReceiver reads callbacks from a broker topic. Messages can't be
+// replied to, every one of them counts as accepted.
+type brokerReceiver struct {
+	cfg        types.BrokerConfig
+	url        string
+	subscriber broker.Subscriber
+	stream     *callbackStream
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+func newBrokerReceiver(config *types.InputConfig) (Receiver, error) {
+	cfg := config.Receiver.Broker
+	cfg.Kind = receiverType(config)
+
+	subscriber, err := broker.NewSubscriber(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// services publish to a fixed topic, so the topic is what gets
+	// injected as reply path
+	url := config.Receiver.PublicURL
+	if url == "" {
+		url = cfg.Topic
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &brokerReceiver{
+		cfg:        cfg,
+		url:        url,
+		subscriber: subscriber,
+		stream:     newCallbackStream(),
+		ctx:        ctx,
+		cancel:     cancel,
+	}, nil
+}
+
+func (rc *brokerReceiver) Start(ctx context.Context) error {
+	err := rc.subscriber.Subscribe(ctx, func(msg broker.Message) {
+		rc.stream.send(&Callback{
+			Header:     msg.Header,
+			Body:       msg.Body,
+			ReceivedAt: msg.Time,
+			Source:     msg.Topic,
+			Context:    rc.ctx,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Initialised receiver...", "type", rc.cfg.Kind, "topic", rc.cfg.Topic)
+	return nil
+}
+
+func (rc *brokerReceiver) URL() string {
+	return rc.url
+}
+
+func (rc *brokerReceiver) Callbacks() <-chan *Callback {
+	return rc.stream.ch
+}
+
+func (rc *brokerReceiver) Shutdown(ctx context.Context) error {
+	// unblocks messages still waiting to be handed over
+	rc.cancel()
+	err := rc.subscriber.Close()
+	rc.stream.close()
+	return err
+}
@@ -0,0 +1,151 @@
+package webhook_tester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	natsserver "github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/broker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// startNATS runs an embedded nats server and returns its url.
+func startNATS(t *testing.T) string {
+	server, err := natsserver.NewServer(&natsserver.Options{Host: "127.0.0.1", Port: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Start()
+	t.Cleanup(server.Shutdown)
+	if !server.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	return server.ClientURL()
+}
+
+// startKafka runs an in-process kafka cluster with topics and returns its
+// seed brokers.
+func startKafka(t *testing.T, topics ...string) []string {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, topics...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cluster.Close)
+	return cluster.ListenAddrs()
+}
+
+// startRedis runs an in-process redis and returns its address.
+func startRedis(t *testing.T) string {
+	return miniredis.RunT(t).Addr()
+}
+
+// startBrokerReceiver starts a receiver of the given kind and returns a
+// tester waiting for req-1 and req-2.
+func startBrokerReceiver(t *testing.T, kind string, cfg types.BrokerConfig) *DefaultWebhookTester {
+	wt := newReceiverTestTester(t)
+	wt.Expect([]string{"req-2"})
+	wt.config.Test.Pickers.CorrelationPicker.Path = "headers.x-correlation-id"
+	wt.config.Receiver.Type = kind
+	wt.config.Receiver.Broker = cfg
+	// reply rules can't apply to broker messages
+	wt.config.Receiver.Responses = []types.ResponseRule{{Status: 500}}
+	wt.config.Test.Timeout = 5
+
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	t.Cleanup(stop)
+
+	if url := wt.ReceiverURL(); url != cfg.Topic {
+		t.Errorf("Expected the topic to be advertised, got %s", url)
+	}
+	return wt
+}
+
+func waitForBrokerCallbacks(t *testing.T, wt *DefaultWebhookTester) {
+	if err := wt.WaitForResults(); err != nil {
+		t.Fatalf("Expected broker messages to complete requests, got %v", err)
+	}
+	// the message without a correlation id is kept as an orphan, it is
+	// handled alongside the others so it may land a little later
+	deadline := time.Now().Add(2 * time.Second)
+	for len(wt.internal.reqTracker.Orphans()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if orphans := wt.internal.reqTracker.Orphans(); len(orphans) != 1 {
+		t.Errorf("Expected 1 orphan, got %d", len(orphans))
+	}
+}
+
+func TestBrokerReceiver_NATS(t *testing.T) {
+	url := startNATS(t)
+	wt := startBrokerReceiver(t, broker.KindNATS, types.BrokerConfig{URL: url, Topic: "jobs.done"})
+
+	conn, err := nats.Connect(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, id := range []string{"req-1", "req-2", ""} {
+		msg := nats.NewMsg("jobs.done")
+		msg.Data = []byte(`{"status": "done"}`)
+		if id != "" {
+			// lower case on purpose, nats keeps header names as they are
+			msg.Header["x-correlation-id"] = []string{id}
+		}
+		if err := conn.PublishMsg(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitForBrokerCallbacks(t, wt)
+}
+
+func TestBrokerReceiver_Kafka(t *testing.T) {
+	brokers := startKafka(t, "jobs-done")
+	wt := startBrokerReceiver(t, broker.KindKafka, types.BrokerConfig{Brokers: brokers, Topic: "jobs-done"})
+
+	producer, err := kgo.NewClient(kgo.SeedBrokers(brokers...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+	for _, id := range []string{"req-1", "req-2", ""} {
+		record := &kgo.Record{Topic: "jobs-done", Value: []byte(`{"status": "done"}`)}
+		if id != "" {
+			record.Headers = []kgo.RecordHeader{{Key: "X-Correlation-Id", Value: []byte(id)}}
+		}
+		if err := producer.ProduceSync(context.Background(), record).FirstErr(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitForBrokerCallbacks(t, wt)
+}
+
+func TestBrokerReceiver_RedisStream(t *testing.T) {
+	addr := startRedis(t)
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	// entries added before the receiver started are not callbacks
+	client.XAdd(context.Background(), &redis.XAddArgs{Stream: "jobs", Values: map[string]any{"body": "{}", "x-correlation-id": "stale"}})
+	time.Sleep(2 * time.Millisecond)
+
+	wt := startBrokerReceiver(t, broker.KindRedis, types.BrokerConfig{URL: "redis://" + addr, Topic: "jobs"})
+
+	for _, id := range []string{"req-1", "req-2", ""} {
+		values := map[string]any{"body": `{"status": "done"}`}
+		if id != "" {
+			values["x-correlation-id"] = id
+		}
+		if err := client.XAdd(context.Background(), &redis.XAddArgs{Stream: "jobs", Values: values}).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitForBrokerCallbacks(t, wt)
+}
@@ -0,0 +1,163 @@
+package webhook_tester
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// httpReceiver accepts callbacks over http, https or a unix domain socket.
+type httpReceiver struct {
+	kind   string
+	config types.ReceiverConfig
+	server *http.Server
+	stream *callbackStream
+	url    string
+}
+
+func newHTTPReceiver(config *types.InputConfig) (Receiver, error) {
+	kind := receiverType(config)
+	if kind == ReceiverUnix && config.Receiver.Socket == "" {
+		return nil, errors.New("Unix receiver needs a socket path")
+	}
+	return &httpReceiver{
+		kind:   kind,
+		config: config.Receiver,
+		stream: newCallbackStream(),
+	}, nil
+}
+
+func (rc *httpReceiver) Start(ctx context.Context) error {
+	receiverConfig := rc.config
+
+	// listen before advertising anything so that a busy port fails the run
+	// and port 0 resolves to the port actually picked
+	var listener net.Listener
+	var err error
+	if rc.kind == ReceiverUnix {
+		// a socket left behind by an earlier run would make listen fail
+		os.Remove(receiverConfig.Socket)
+		listener, err = net.Listen("unix", receiverConfig.Socket)
+	} else {
+		listener, err = net.Listen("tcp", receiverConfig.Listen)
+	}
+	if err != nil {
+		return err
+	}
+
+	scheme := "http"
+	if rc.kind == ReceiverHTTPS {
+		tlsConfig, err := receiverTLSConfig(receiverConfig.TLS, receiverConfig.PublicURL)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+		scheme = "https"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(receiverConfig.Path, callbackHandler(rc.stream))
+	rc.server = &http.Server{Handler: mux}
+	go func() {
+		if err := rc.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("Receiver stopped", "err", err)
+		}
+	}()
+
+	rc.url = receiverConfig.PublicURL
+	if rc.url == "" {
+		if rc.kind == ReceiverUnix {
+			rc.url = "http+unix://" + url.PathEscape(receiverConfig.Socket) + receiverConfig.Path
+		} else {
+			rc.url = localURL(scheme, listener.Addr(), receiverConfig.Path)
+		}
+	}
+	slog.Info("Initialised receiver...", "type", rc.kind, "addr", listener.Addr(), "url", rc.url)
+	return nil
+}
+
+func (rc *httpReceiver) URL() string {
+	return rc.url
+}
+
+func (rc *httpReceiver) Callbacks() <-chan *Callback {
+	return rc.stream.ch
+}
+
+func (rc *httpReceiver) Shutdown(ctx context.Context) error {
+	defer rc.stream.close()
+	if rc.server == nil {
+		return nil
+	}
+
+	err := rc.server.Shutdown(ctx)
+	if err != nil {
+		// hanging replies only end once their connection does
+		rc.server.Close()
+	}
+	if rc.kind == ReceiverUnix {
+		os.Remove(rc.config.Socket)
+	}
+	return err
+}
+
+// localURL builds the url of a receiver listening on addr. Wildcard hosts
+// are advertised as localhost.
+func localURL(scheme string, addr net.Addr, path string) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return scheme + "://" + addr.String() + path
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port) + path
+}
+
+// callbackHandler turns http requests into callbacks on stream and holds
+// on to them until they are replied to.
+func callbackHandler(stream *callbackStream) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cb, replied := newHTTPCallback(w, r)
+		if !stream.send(cb) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		<-replied
+	})
+}
+
+// newHTTPCallback wraps an http request, the returned channel is closed
+// once the callback was replied to.
+func newHTTPCallback(w http.ResponseWriter, r *http.Request) (*Callback, <-chan struct{}) {
+	receivedAt := time.Now()
+	body, _ := io.ReadAll(r.Body)
+
+	replied := make(chan struct{})
+	var once sync.Once
+	return &Callback{
+		Header:     r.Header,
+		Body:       body,
+		ReceivedAt: receivedAt,
+		Method:     r.Method,
+		Source:     r.URL.String(),
+		Context:    r.Context(),
+		Reply: func(rule types.ResponseRule) {
+			once.Do(func() {
+				writeResponse(w, r, rule)
+				close(replied)
+			})
+		},
+	}, replied
+}
@@ -0,0 +1,77 @@
+package webhook_tester
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"golang.ngrok.com/ngrok"
+	ngrokconfig "golang.ngrok.com/ngrok/config"
+)
+
+// ngrokReceiver exposes the receiver through an ngrok tunnel, it needs
+// NGROK_AUTHTOKEN to be set.
+type ngrokReceiver struct {
+	path   string
+	server *http.Server
+	stream *callbackStream
+	url    string
+}
+
+func newNgrokReceiver(config *types.InputConfig) (Receiver, error) {
+	return &ngrokReceiver{
+		path:   config.Receiver.Path,
+		stream: newCallbackStream(),
+	}, nil
+}
+
+func (rc *ngrokReceiver) Start(ctx context.Context) error {
+	if _, found := os.LookupEnv("NGROK_AUTHTOKEN"); !found {
+		return types.NgrokAuthMissingErr
+	}
+
+	listener, err := ngrok.Listen(ctx,
+		ngrokconfig.HTTPEndpoint(),
+		ngrok.WithAuthtokenFromEnv(),
+	)
+	if err != nil {
+		return err
+	}
+	slog.Debug("Ingress established at:", "addr", listener.URL())
+
+	mux := http.NewServeMux()
+	mux.Handle(rc.path, callbackHandler(rc.stream))
+	rc.server = &http.Server{Handler: mux}
+	go func() {
+		if err := rc.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("Receiver stopped", "err", err)
+		}
+	}()
+
+	rc.url = strings.TrimSuffix(listener.URL(), "/") + rc.path
+	slog.Info("Initialised receiver...", "type", ReceiverNgrok, "url", rc.url)
+	return nil
+}
+
+func (rc *ngrokReceiver) URL() string {
+	return rc.url
+}
+
+func (rc *ngrokReceiver) Callbacks() <-chan *Callback {
+	return rc.stream.ch
+}
+
+func (rc *ngrokReceiver) Shutdown(ctx context.Context) error {
+	defer rc.stream.close()
+	if rc.server == nil {
+		return nil
+	}
+	err := rc.server.Shutdown(ctx)
+	if err != nil {
+		rc.server.Close()
+	}
+	return err
+}
@@ -0,0 +1,208 @@
+package webhook_tester
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/stream"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const (
+	DEFAULT_MAX_RECONNECT_DELAY = 30 * time.Second
+	minReconnectDelay           = 500 * time.Millisecond
+)
+
+func init() {
+	RegisterReceiver(stream.KindWebSocket, newStreamReceiver)
+	RegisterReceiver(stream.KindSSE, newStreamReceiver)
+}
+
+// DisconnectionReporter is implemented by receivers that keep a connection
+// to the provider open, the drops they saw are added to the report.
+type DisconnectionReporter interface {
+	Disconnections() []tracker.Disconnection
+}
+
+// streamReceiver connects to a websocket or sse stream and reads every
+// event as a callback. Dropped connections are retried with a backoff
+// until the receiver is shut down.
+type streamReceiver struct {
+	kind       string
+	cfg        types.StreamConfig
+	header     http.Header
+	maxBackoff time.Duration
+	stream     *callbackStream
+	ctx        context.Context
+	cancel     context.CancelFunc
+	done       chan struct{}
+
+	lock           sync.Mutex
+	conn           stream.Conn
+	lastEventID    string
+	disconnections []tracker.Disconnection
+}
+
+func newStreamReceiver(config *types.InputConfig) (Receiver, error) {
+	cfg := config.Receiver.Stream
+
+	header := http.Header{}
+	for key, value := range cfg.Headers {
+		header.Set(key, os.ExpandEnv(value))
+	}
+	maxBackoff := DEFAULT_MAX_RECONNECT_DELAY
+	if cfg.MaxReconnectSeconds > 0 {
+		maxBackoff = time.Duration(cfg.MaxReconnectSeconds) * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &streamReceiver{
+		kind:       receiverType(config),
+		cfg:        cfg,
+		header:     header,
+		maxBackoff: maxBackoff,
+		stream:     newCallbackStream(),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}, nil
+}
+
+func (rc *streamReceiver) Start(ctx context.Context) error {
+	conn, err := stream.Dial(ctx, rc.kind, rc.cfg.URL, rc.header)
+	if err != nil {
+		return err
+	}
+	rc.conn = conn
+	go rc.run(conn)
+
+	slog.Info("Initialised receiver...", "type", rc.kind, "url", rc.cfg.URL)
+	return nil
+}
+
+// run reads conn until it drops, then reconnects, until shut down.
+func (rc *streamReceiver) run(conn stream.Conn) {
+	defer close(rc.done)
+
+	for {
+		err := rc.read(conn)
+		if rc.ctx.Err() != nil {
+			return
+		}
+
+		dropped := tracker.Disconnection{Time: time.Now(), Reason: err.Error()}
+		slog.Warn("Stream connection dropped, reconnecting", "url", rc.cfg.URL, "err", err)
+
+		conn = rc.reconnect()
+		dropped.Downtime = time.Since(dropped.Time)
+		dropped.Recovered = conn != nil
+
+		rc.lock.Lock()
+		rc.disconnections = append(rc.disconnections, dropped)
+		rc.lock.Unlock()
+
+		if conn == nil {
+			return
+		}
+		slog.Info("Stream connection back", "url", rc.cfg.URL, "after", dropped.Downtime)
+	}
+}
+
+// read hands every event of conn over as a callback until it fails.
+func (rc *streamReceiver) read(conn stream.Conn) error {
+	for {
+		event, err := conn.Next()
+		if err != nil {
+			return err
+		}
+		if id := event.Header.Get("Last-Event-Id"); id != "" {
+			rc.lock.Lock()
+			rc.lastEventID = id
+			rc.lock.Unlock()
+		}
+		rc.stream.send(&Callback{
+			Header:     event.Header,
+			Body:       event.Body,
+			ReceivedAt: event.Time,
+			Source:     rc.cfg.URL,
+			Context:    rc.ctx,
+		})
+	}
+}
+
+// reconnect dials until it succeeds, doubling the wait after every failed
+// attempt. It gives up, returning nil, once the receiver is shut down.
+func (rc *streamReceiver) reconnect() stream.Conn {
+	backoff := minReconnectDelay
+	for {
+		select {
+		case <-rc.ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		header := rc.header.Clone()
+		rc.lock.Lock()
+		if rc.lastEventID != "" {
+			// lets sse servers resume after the last event seen
+			header.Set("Last-Event-Id", rc.lastEventID)
+		}
+		rc.lock.Unlock()
+
+		conn, err := stream.Dial(rc.ctx, rc.kind, rc.cfg.URL, header)
+		if err != nil {
+			slog.Debug("Failed to reconnect", "url", rc.cfg.URL, "err", err, "retryIn", backoff)
+			backoff = min(2*backoff, rc.maxBackoff)
+			continue
+		}
+
+		rc.lock.Lock()
+		defer rc.lock.Unlock()
+		if rc.ctx.Err() != nil {
+			conn.Close()
+			return nil
+		}
+		rc.conn = conn
+		return conn
+	}
+}
+
+func (rc *streamReceiver) URL() string {
+	return rc.cfg.URL
+}
+
+func (rc *streamReceiver) Callbacks() <-chan *Callback {
+	return rc.stream.ch
+}
+
+func (rc *streamReceiver) Disconnections() []tracker.Disconnection {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	return append([]tracker.Disconnection(nil), rc.disconnections...)
+}
+
+func (rc *streamReceiver) Shutdown(ctx context.Context) error {
+	rc.cancel()
+
+	rc.lock.Lock()
+	conn := rc.conn
+	var err error
+	if conn != nil {
+		err = conn.Close()
+	}
+	rc.lock.Unlock()
+
+	if conn != nil {
+		select {
+		case <-rc.done:
+		case <-ctx.Done():
+		}
+	}
+	rc.stream.close()
+	return err
+}
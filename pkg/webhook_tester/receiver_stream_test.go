@@ -0,0 +1,153 @@
+package webhook_tester
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/stream"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// streamProvider stands in for a provider announcing completions on a
+// stream. The first connection is dropped after its first event, so the
+// receiver has to reconnect to see the rest.
+type streamProvider struct {
+	events      chan string
+	connections atomic.Int32
+	sent        atomic.Int32
+	// lastEventIDs holds the Last-Event-Id every connection was opened with
+	lastEventIDs chan string
+}
+
+func newStreamProvider() *streamProvider {
+	return &streamProvider{events: make(chan string, 10), lastEventIDs: make(chan string, 10)}
+}
+
+func (p *streamProvider) webSocket(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		first := p.connections.Add(1) == 1
+		for event := range p.events {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(event)); err != nil {
+				return
+			}
+			if first {
+				return
+			}
+		}
+	}
+}
+
+func (p *streamProvider) sse(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer s3cret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	p.lastEventIDs <- r.Header.Get("Last-Event-Id")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": keep-alive\n\n")
+	w.(http.Flusher).Flush()
+
+	first := p.connections.Add(1) == 1
+	for event := range p.events {
+		fmt.Fprintf(w, "event: job.done\nid: %d\ndata: %s\n\n", p.sent.Add(1), event)
+		w.(http.Flusher).Flush()
+		if first {
+			return
+		}
+	}
+}
+
+func startStreamReceiver(t *testing.T, kind, url string) *DefaultWebhookTester {
+	t.Setenv("PROVIDER_TOKEN", "s3cret")
+
+	wt := newReceiverTestTester(t)
+	wt.Expect([]string{"req-2"})
+	wt.config.Receiver.Type = kind
+	wt.config.Receiver.Stream = types.StreamConfig{
+		URL:     url,
+		Headers: map[string]string{"Authorization": "Bearer ${PROVIDER_TOKEN}"},
+	}
+	wt.config.Test.Timeout = 5
+
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	t.Cleanup(stop)
+	return wt
+}
+
+func waitForStreamCallbacks(t *testing.T, wt *DefaultWebhookTester, provider *streamProvider) {
+	provider.events <- `{"uniqueId": "req-1"}`
+	provider.events <- `{"uniqueId": "req-2"}`
+	if err := wt.WaitForResults(); err != nil {
+		t.Fatalf("Expected events from both connections to complete requests, got %v", err)
+	}
+
+	drops := wt.internal.receiver.(DisconnectionReporter).Disconnections()
+	if len(drops) != 1 || !drops[0].Recovered {
+		t.Fatalf("Expected one recovered drop, got %+v", drops)
+	}
+
+	var report bytes.Buffer
+	reporter.PrintTextMetrics(&report, reporter.Metrics{Disconnections: reporter.CalculateDisconnectionMetrics(drops)})
+	if !strings.Contains(report.String(), "Connection Drops              : 1") {
+		t.Errorf("Expected the drop in the report, got\n%s", report.String())
+	}
+}
+
+func TestStreamReceiver_WebSocket(t *testing.T) {
+	provider := newStreamProvider()
+	server := httptest.NewServer(provider.webSocket(t))
+	defer server.Close()
+	defer close(provider.events)
+
+	wt := startStreamReceiver(t, stream.KindWebSocket, "ws"+strings.TrimPrefix(server.URL, "http"))
+	waitForStreamCallbacks(t, wt, provider)
+}
+
+func TestStreamReceiver_SSE(t *testing.T) {
+	provider := newStreamProvider()
+	server := httptest.NewServer(http.HandlerFunc(provider.sse))
+	defer server.Close()
+	defer close(provider.events)
+
+	wt := startStreamReceiver(t, stream.KindSSE, server.URL)
+	waitForStreamCallbacks(t, wt, provider)
+
+	if first, second := <-provider.lastEventIDs, <-provider.lastEventIDs; first != "" || second == "" {
+		t.Errorf("Expected the reconnect to resume after the last event id, got %q then %q", first, second)
+	}
+}
+
+func TestStreamReceiver_RejectedHandshake(t *testing.T) {
+	provider := newStreamProvider()
+	server := httptest.NewServer(http.HandlerFunc(provider.sse))
+	defer server.Close()
+
+	wt := newReceiverTestTester(t)
+	wt.config.Receiver.Type = stream.KindSSE
+	wt.config.Receiver.Stream = types.StreamConfig{URL: server.URL}
+	if _, err := wt.StartReceiver(); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected the rejected connection to fail the receiver, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package webhook_tester
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func newReceiverTestTester(t *testing.T) *DefaultWebhookTester {
+	var config types.InputConfig
+	config.Test.Injectors.ReplyPathInjector.Path = "headers.webhook-reply-to"
+	config.Test.Injectors.CorrelationIDInjector.Path = "body.uniqueId"
+	config.Test.Pickers.CorrelationPicker.Path = "body.uniqueId"
+	config.Run.Iterations = 1
+	config.Run.DurationSeconds = 1
+
+	wt := NewDefaultWebhookTester(&config)
+	if err := wt.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	wt.Expect([]string{"req-1"})
+	wt.internal.reqTracker.Update("req-1", func(r *tracker.RequestTrackerPair) {
+		r.StartTime = time.Now()
+	})
+	return wt
+}
+
+func deliver(wt *DefaultWebhookTester, body string, headers map[string]string) int {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	cb, _ := newHTTPCallback(rec, req)
+	wt.handleCallback(cb)
+	return rec.Code
+}
+
+func TestReceiverHandler_TracksRedeliveries(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	wt.config.Test.Pickers.DeliveryIDPicker.Path = "headers.webhook-id"
+
+	deliver(wt, `{"uniqueId": "req-1"}`, map[string]string{"webhook-id": "msg_1"})
+	deliver(wt, `{"uniqueId": "req-1"}`, map[string]string{"webhook-id": "msg_1"})
+	deliver(wt, `{"uniqueId": "req-1"}`, map[string]string{"webhook-id": "msg_2"})
+
+	if err := wt.WaitForResults(); err != nil {
+		t.Fatalf("Expected request to complete, got %v", err)
+	}
+
+	record := wt.Records()["req-1"]
+	if record.EndTime.IsZero() {
+		t.Fatalf("Expected first delivery time to be kept")
+	}
+	if len(record.Redeliveries) != 2 {
+		t.Errorf("Expected 2 redeliveries, got %d", len(record.Redeliveries))
+	}
+	if record.Duplicates != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", record.Duplicates)
+	}
+}
+
+func TestReceiverHandler_CompletesOnFirstAcceptedAttempt(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	wt.config.Receiver.Responses = []types.ResponseRule{{Attempt: 1, Status: http.StatusServiceUnavailable}}
+
+	if code := deliver(wt, `{"uniqueId": "req-1"}`, nil); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected first attempt to be rejected, got %d", code)
+	}
+	if record := wt.Records()["req-1"]; !record.EndTime.IsZero() {
+		t.Errorf("Expected rejected attempt not to complete the request")
+	}
+
+	if code := deliver(wt, `{"uniqueId": "req-1"}`, nil); code != http.StatusOK {
+		t.Errorf("Expected retry to be accepted, got %d", code)
+	}
+	record := wt.Records()["req-1"]
+	if len(record.Attempts) != 2 || len(record.Redeliveries) != 0 {
+		t.Errorf("Expected 2 attempts and no redelivery, got %+v", record)
+	}
+}
+
+func TestReceiverHandler_TimesCallbacksByArrival(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	received := time.Now().Add(-time.Second)
+	wt.handleCallback(&Callback{
+		Header:     http.Header{},
+		Body:       []byte(`{"uniqueId": "req-1"}`),
+		ReceivedAt: received,
+		Context:    context.Background(),
+	})
+	if record := wt.Records()["req-1"]; !record.EndTime.Equal(received) {
+		t.Errorf("Expected the callback to be timed when it was received, got %s instead of %s", record.EndTime, received)
+	}
+}
+
+func TestReceiverHandler_RecordsOrphans(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	wt.config.Receiver.OrphanStatus = http.StatusNotFound
+
+	cases := map[string]string{
+		`not json`:              tracker.OrphanMalformedBody,
+		`{"other": "field"}`:    tracker.OrphanMissingCorrelationID,
+		`{"uniqueId": 42}`:      tracker.OrphanInvalidCorrelationID,
+		`{"uniqueId": "req-2"}`: tracker.OrphanUnknownCorrelationID,
+	}
+	for body := range cases {
+		if code := deliver(wt, body, nil); code != http.StatusNotFound {
+			t.Errorf("%s: expected orphan status, got %d", body, code)
+		}
+	}
+
+	orphans := wt.internal.reqTracker.Orphans()
+	if len(orphans) != len(cases) {
+		t.Fatalf("Expected %d orphans, got %d", len(cases), len(orphans))
+	}
+	for _, orphan := range orphans {
+		if cases[orphan.Body] != orphan.Reason {
+			t.Errorf("%s: expected reason %q, got %q", orphan.Body, cases[orphan.Body], orphan.Reason)
+		}
+	}
+	if _, found := wt.Records()["req-2"]; found {
+		t.Errorf("Expected no tracker entry for unknown correlation id")
+	}
+
+	// the expected request is still pending
+	wt.config.Test.Timeout = 0
+	if err := wt.WaitForResults(); err == nil {
+		t.Errorf("Expected orphans not to complete pending requests")
+	}
+}
+
+// chanReceiver is a custom backend fed straight from the test.
+type chanReceiver struct {
+	stream *callbackStream
+}
+
+func (rc *chanReceiver) Start(ctx context.Context) error    { return nil }
+func (rc *chanReceiver) URL() string                        { return "test://receiver" }
+func (rc *chanReceiver) Callbacks() <-chan *Callback        { return rc.stream.ch }
+func (rc *chanReceiver) Shutdown(ctx context.Context) error { rc.stream.close(); return nil }
+
+func TestStartReceiver_UsesRegisteredBackend(t *testing.T) {
+	backend := &chanReceiver{stream: newCallbackStream()}
+	RegisterReceiver("test", func(config *types.InputConfig) (Receiver, error) {
+		return backend, nil
+	})
+
+	wt := newReceiverTestTester(t)
+	wt.config.Receiver.Type = "test"
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	defer stop()
+
+	if url := wt.ReceiverURL(); url != "test://receiver" {
+		t.Errorf("Expected the backend's url to be advertised, got %s", url)
+	}
+
+	// backends that can't reply leave Reply nil
+	backend.stream.send(&Callback{
+		Header:     http.Header{},
+		Body:       []byte(`{"uniqueId": "req-1"}`),
+		ReceivedAt: time.Now(),
+		Context:    context.Background(),
+	})
+	if err := wt.WaitForResults(); err != nil {
+		t.Errorf("Expected callback from the custom backend to complete the request, got %v", err)
+	}
+}
+
+func TestStartReceiver_UnixSocket(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	wt.config.Receiver.Type = ReceiverUnix
+	wt.config.Receiver.Socket = filepath.Join(t.TempDir(), "wlt.sock")
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	defer stop()
+
+	if url := wt.ReceiverURL(); !strings.HasPrefix(url, "http+unix://") {
+		t.Errorf("Expected a unix socket url, got %s", url)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", wt.config.Receiver.Socket)
+		},
+	}}
+	res, err := client.Post("http://unix/", "application/json", strings.NewReader(`{"uniqueId": "req-1"}`))
+	if err != nil {
+		t.Fatalf("Failed to deliver over the socket: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", res.StatusCode)
+	}
+	if err := wt.WaitForResults(); err != nil {
+		t.Errorf("Expected request to complete, got %v", err)
+	}
+}
+
+func TestStartReceiver_ListensOnPickedPort(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	wt.config.Receiver.Listen = "127.0.0.1:0"
+	wt.config.Receiver.Path = "/hooks/done"
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	defer stop()
+
+	receiverURL, err := url.Parse(wt.ReceiverURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receiverURL.Port() == "" || receiverURL.Port() == "0" || receiverURL.Path != "/hooks/done" {
+		t.Fatalf("Expected the picked port and the path to be advertised, got %s", receiverURL)
+	}
+
+	post := func(path string) int {
+		res, err := http.Post("http://"+receiverURL.Host+path, "application/json", strings.NewReader(`{"uniqueId": "req-1"}`))
+		if err != nil {
+			t.Fatalf("Failed to deliver: %v", err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+	if code := post("/elsewhere"); code != http.StatusNotFound {
+		t.Errorf("Expected callbacks on other paths to be refused, got %d", code)
+	}
+	if code := post("/hooks/done"); code != http.StatusOK {
+		t.Errorf("Expected the callback to be accepted, got %d", code)
+	}
+	if err := wt.WaitForResults(); err != nil {
+		t.Errorf("Expected request to complete, got %v", err)
+	}
+}
+
+func TestStartReceiver_PublicURLIsInjected(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	wt.config.Receiver.Listen = "127.0.0.1:0"
+	wt.config.Receiver.PublicURL = "https://hooks.example.test/callbacks"
+	wt.config.Test.Body = `{"kind": "resize"}`
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	defer stop()
+
+	if advertised := wt.ReceiverURL(); advertised != "https://hooks.example.test/callbacks" {
+		t.Errorf("Expected the public url to be advertised, got %s", advertised)
+	}
+	msg, err := wt.buildMessage("req-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replyTo := msg.Header.Get("webhook-reply-to"); replyTo != "https://hooks.example.test/callbacks" {
+		t.Errorf("Expected the public url to be injected into triggers, got %q", replyTo)
+	}
+}
+
+func TestStartReceiver_UnknownType(t *testing.T) {
+	wt := newReceiverTestTester(t)
+	wt.config.Receiver.Type = "carrier-pigeon"
+	if _, err := wt.StartReceiver(); err == nil {
+		t.Errorf("Expected an unknown receiver type to fail")
+	}
+}
@@ -0,0 +1,80 @@
+package webhook_tester
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+var defaultResponse = types.ResponseRule{Status: http.StatusOK}
+
+// responseRand draws whether probability rules apply. It is shared by
+// every callback, a seeded run draws the same sequence every time.
+type responseRand struct {
+	lock sync.Mutex
+	rnd  *rand.Rand
+}
+
+func newResponseRand(seed int64) *responseRand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &responseRand{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (r *responseRand) Float64() float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.rnd.Float64()
+}
+
+// pickResponse returns the first rule matching the given attempt number,
+// rules with a probability only match when rnd draws below it.
+func pickResponse(rules []types.ResponseRule, attempt int, rnd *responseRand) types.ResponseRule {
+	for _, rule := range rules {
+		if rule.Attempt != 0 && rule.Attempt != attempt {
+			continue
+		}
+		if rule.Probability != 0 && rnd.Float64() >= rule.Probability {
+			continue
+		}
+		if rule.Status == 0 {
+			rule.Status = http.StatusOK
+		}
+		return rule
+	}
+	return defaultResponse
+}
+
+// accepts reports whether the provider will treat the reply as delivered.
+func accepts(rule types.ResponseRule) bool {
+	return !rule.Hang && rule.Status >= 200 && rule.Status < 300
+}
+
+// writeResponse replies to the callback as described by rule.
+func writeResponse(w http.ResponseWriter, r *http.Request, rule types.ResponseRule) {
+	if rule.Hang {
+		// hold the connection until the provider gives up
+		<-r.Context().Done()
+		return
+	}
+
+	if rule.DelayMs > 0 {
+		select {
+		case <-time.After(time.Duration(rule.DelayMs) * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	for k, v := range rule.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(rule.Status)
+	if rule.Body != "" {
+		w.Write([]byte(rule.Body))
+	}
+}
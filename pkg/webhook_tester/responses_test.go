@@ -0,0 +1,97 @@
+package webhook_tester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func TestPickResponse_ByAttempt(t *testing.T) {
+	rules := []types.ResponseRule{
+		{Attempt: 1, Status: http.StatusInternalServerError},
+		{Attempt: 2, Status: http.StatusServiceUnavailable},
+		{Body: `{"ok": true}`},
+	}
+	rnd := newResponseRand(1)
+
+	for attempt, want := range map[int]int{1: 500, 2: 503, 3: 200} {
+		if got := pickResponse(rules, attempt, rnd); got.Status != want {
+			t.Errorf("Attempt %d: expected %d, got %d", attempt, want, got.Status)
+		}
+	}
+	// rules without a status accept the callback
+	if got := pickResponse(rules, 3, rnd); got.Body != `{"ok": true}` {
+		t.Errorf("Expected the catch-all rule, got %+v", got)
+	}
+	if got := pickResponse(nil, 1, rnd); got.Status != http.StatusOK || got.Body != "" {
+		t.Errorf("Expected an empty 200 without rules, got %+v", got)
+	}
+}
+
+func TestPickResponse_Probabilities(t *testing.T) {
+	rules := []types.ResponseRule{
+		{Probability: 0.1, Hang: true},
+		{Probability: 0.25, Status: http.StatusServiceUnavailable},
+	}
+	draw := func(seed int64) map[string]int {
+		rnd := newResponseRand(seed)
+		counts := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			switch rule := pickResponse(rules, 1, rnd); {
+			case rule.Hang:
+				counts["hang"]++
+			default:
+				counts[http.StatusText(rule.Status)]++
+			}
+		}
+		return counts
+	}
+
+	counts := draw(42)
+	// 10% hang, 25% of the other 90% are told to come back later
+	for outcome, want := range map[string]int{"hang": 1000, "Service Unavailable": 2250, "OK": 6750} {
+		if got := counts[outcome]; got < want*9/10 || got > want*11/10 {
+			t.Errorf("%s: expected about %d, got %d", outcome, want, got)
+		}
+	}
+
+	again := draw(42)
+	for outcome := range counts {
+		if again[outcome] != counts[outcome] {
+			t.Errorf("Expected the same seed to inject the same failures, got %v and %v", counts, again)
+			break
+		}
+	}
+}
+
+func TestWriteResponse(t *testing.T) {
+	rule := types.ResponseRule{
+		Status:  http.StatusServiceUnavailable,
+		Body:    "later",
+		Headers: map[string]string{"Retry-After": "1"},
+		DelayMs: 50,
+	}
+	rec := httptest.NewRecorder()
+	started := time.Now()
+	writeResponse(rec, httptest.NewRequest(http.MethodPost, "/", nil), rule)
+
+	if waited := time.Since(started); waited < 50*time.Millisecond {
+		t.Errorf("Expected the reply to be held back 50ms, took %s", waited)
+	}
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != "later" || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Unexpected reply %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	// hanging replies only end once the provider gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	rec = httptest.NewRecorder()
+	writeResponse(rec, httptest.NewRequest(http.MethodPost, "/", nil).WithContext(ctx), types.ResponseRule{Hang: true})
+	if rec.Flushed || rec.Body.Len() != 0 || ctx.Err() == nil {
+		t.Errorf("Expected nothing to be written before the provider gave up")
+	}
+}
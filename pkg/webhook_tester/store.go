@@ -0,0 +1,84 @@
+package webhook_tester
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/reporter"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+// configMetaKey is where bolt stores keep the config of their run.
+const configMetaKey = "config"
+
+// openTrackerStore creates the store config.tracker asks for. Bolt stores
+// keep the config next to the records so ReportStore can tell what the run
+// was.
+func openTrackerStore(config *types.InputConfig) (tracker.Store, error) {
+	store, err := tracker.Open(config.Tracker.Store, config.Tracker.Path)
+	if err != nil {
+		return nil, err
+	}
+	if bolt, ok := store.(*tracker.BoltStore); ok {
+		raw, err := yaml.Marshal(config)
+		if err == nil {
+			err = bolt.SetMeta(configMetaKey, raw)
+		}
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// storeMetrics calculates the metrics of the run recorded in store.
+func storeMetrics(store tracker.Store, config *types.InputConfig) (reporter.Metrics, error) {
+	metrics, err := reporter.CalculateStoreMetrics(
+		store,
+		time.Duration(config.Run.DurationSeconds)*time.Second,
+		time.Duration(config.Report.BucketSeconds)*time.Second,
+	)
+	if err != nil {
+		return metrics, err
+	}
+	metrics.Orphans = reporter.CalculateOrphanMetrics(store.OrphanCounts(), store.Orphans())
+	return metrics, nil
+}
+
+// ReportStore writes the report of the run recorded in the bolt store at
+// path, eg. after the tester crashed before post processing. Requests
+// still waiting for a callback are reported as timed out. The outputs of
+// the recorded config are used when outputs is empty, stdout when it has
+// none either.
+func ReportStore(path string, outputs []types.OutputConfig) error {
+	store, err := tracker.OpenBoltStore(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	raw := store.Meta(configMetaKey)
+	if raw == nil {
+		return errors.New("Tracker store has no config: " + path)
+	}
+	var config types.InputConfig
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return err
+	}
+	if len(outputs) == 0 {
+		outputs = config.Outputs
+	}
+	if len(outputs) == 0 {
+		outputs = []types.OutputConfig{{Type: "stdout"}}
+	}
+
+	metrics, err := storeMetrics(store, &config)
+	if err != nil {
+		return err
+	}
+	metrics.Thresholds = reporter.EvaluateThresholds(metrics, config.Report.Thresholds)
+	return WriteOutputs(outputs, reporter.Report{Metrics: metrics, Records: store, Config: &config})
+}
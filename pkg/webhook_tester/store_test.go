@@ -0,0 +1,47 @@
+package webhook_tester
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func TestBoltTracker_ReportStore(t *testing.T) {
+	dir := t.TempDir()
+	config := loadGraphQLConfig(t)
+	config.Tracker = types.TrackerConfig{Store: tracker.StoreBolt, Path: filepath.Join(dir, "run.db")}
+	config.Outputs = []types.OutputConfig{{Type: "text", Path: filepath.Join(dir, "run.txt")}}
+
+	wt, err := runGraphQLTrigger(t, config)
+	if err != nil {
+		t.Fatalf("Expected every operation to be called back, got %v", err)
+	}
+	wt.WaitForRequests()
+	if _, ok := wt.internal.reqTracker.(*tracker.BoltStore); !ok {
+		t.Fatalf("Expected records to be kept on disk, got %T", wt.internal.reqTracker)
+	}
+	if err := wt.PostProcess(); err != nil {
+		t.Fatalf("PostProcess failed: %v", err)
+	}
+
+	// reporting again from the file gives the same report
+	rerun := filepath.Join(dir, "rerun.txt")
+	if err := ReportStore(config.Tracker.Path, []types.OutputConfig{{Type: "text", Path: rerun}}); err != nil {
+		t.Fatalf("ReportStore failed: %v", err)
+	}
+	original, _ := os.ReadFile(filepath.Join(dir, "run.txt"))
+	reported, _ := os.ReadFile(rerun)
+	if !strings.Contains(string(original), "Total Requests") || string(original) != string(reported) {
+		t.Errorf("Expected the same report from the store, got\n%s\nthen\n%s", original, reported)
+	}
+}
+
+func TestReportStore_Missing(t *testing.T) {
+	if err := ReportStore(filepath.Join(t.TempDir(), "none.db"), nil); err == nil {
+		t.Error("Expected a missing store to be reported")
+	}
+}
@@ -0,0 +1,217 @@
+package webhook_tester
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const DEFAULT_CERTS_DIR = "wlt-certs"
+
+const (
+	caCertFile     = "ca.pem"
+	caKeyFile      = "ca-key.pem"
+	leafCertFile   = "cert.pem"
+	leafKeyFile    = "key.pem"
+	clientCertFile = "client.pem"
+	clientKeyFile  = "client-key.pem"
+)
+
+// receiverTLSConfig builds the tls config of the receiver, either from the
+// supplied certificate or from one issued by a generated CA.
+func receiverTLSConfig(cfg types.ReceiverTLSConfig, publicURL string) (*tls.Config, error) {
+	var cert tls.Certificate
+	var ca *x509.Certificate
+	var err error
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err = tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		cert, ca, err = generateCertificates(cfg, publicURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if !cfg.RequireClientCert {
+		return tlsConfig, nil
+	}
+
+	clientCAs := x509.NewCertPool()
+	switch {
+	case cfg.ClientCAFile != "":
+		pemBytes, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		if !clientCAs.AppendCertsFromPEM(pemBytes) {
+			return nil, errors.New("No certificates found in client CA file: " + cfg.ClientCAFile)
+		}
+	case ca != nil:
+		clientCAs.AddCert(ca)
+	default:
+		return nil, errors.New("clientCaFile is required to verify client certificates when certFile is supplied")
+	}
+
+	tlsConfig.ClientCAs = clientCAs
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
+
+// generateCertificates issues a leaf certificate for the receiver, and a
+// client certificate for mTLS, from a CA kept in cfg.GenerateDir. The CA is
+// reused across runs so that it only needs to be trusted once.
+func generateCertificates(cfg types.ReceiverTLSConfig, publicURL string) (tls.Certificate, *x509.Certificate, error) {
+	dir := cfg.GenerateDir
+	if dir == "" {
+		dir = DEFAULT_CERTS_DIR
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	ca, caKey, err := loadOrCreateCA(dir)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	hosts := cfg.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+	if u, err := url.Parse(publicURL); err == nil && u.Hostname() != "" {
+		hosts = append(hosts, u.Hostname())
+	}
+
+	leafTemplate := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "webhook-load-tester receiver"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			leafTemplate.IPAddresses = append(leafTemplate.IPAddresses, ip)
+		} else {
+			leafTemplate.DNSNames = append(leafTemplate.DNSNames, h)
+		}
+	}
+	leaf, err := issueCertificate(dir, leafCertFile, leafKeyFile, leafTemplate, ca, caKey)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	if cfg.RequireClientCert && cfg.ClientCAFile == "" {
+		clientTemplate := &x509.Certificate{
+			Subject:     pkix.Name{CommonName: "webhook-load-tester client"},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		if _, err := issueCertificate(dir, clientCertFile, clientKeyFile, clientTemplate, ca, caKey); err != nil {
+			return tls.Certificate{}, nil, err
+		}
+	}
+
+	slog.Info("Generated receiver certificates", "dir", dir, "ca", filepath.Join(dir, caCertFile))
+	return leaf, ca, nil
+}
+
+func loadOrCreateCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPath := filepath.Join(dir, caCertFile)
+	keyPath := filepath.Join(dir, caKeyFile)
+
+	if pair, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		ca, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, nil, errors.New("Unsupported CA key type in " + keyPath)
+		}
+		return ca, key, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          randomSerial(),
+		Subject:               pkix.Name{CommonName: "webhook-load-tester CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(5, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writePEM(certPath, keyPath, der, key); err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(der)
+	return ca, key, err
+}
+
+// issueCertificate signs template with the CA and writes it to dir.
+func issueCertificate(dir, certFile, keyFile string, template, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template.SerialNumber = randomSerial()
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().AddDate(1, 0, 0)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	certPath, keyPath := filepath.Join(dir, certFile), filepath.Join(dir, keyFile)
+	if err := writePEM(certPath, keyPath, der, key); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.LoadX509KeyPair(certPath, keyPath)
+}
+
+func writePEM(certPath, keyPath string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(keyPath, keyPEM, 0600)
+}
+
+func randomSerial() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	return serial
+}
@@ -0,0 +1,160 @@
+package webhook_tester
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+// startTLSServer serves 200s over tlsConfig.
+func startTLSServer(t *testing.T, tlsConfig *tls.Config) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// tlsClient trusts the CA in caFile and presents certs, if any.
+func tlsClient(t *testing.T, caFile string, certs ...tls.Certificate) *http.Client {
+	pemBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(pemBytes)
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs},
+	}}
+}
+
+func readCertificate(t *testing.T, path string) *x509.Certificate {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		t.Fatalf("No certificate in %s", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestReceiverTLSConfig_SuppliedCertificate(t *testing.T) {
+	// issue the certificate to supply from a CA of our own
+	dir := t.TempDir()
+	if _, _, err := generateCertificates(types.ReceiverTLSConfig{GenerateDir: dir}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig, err := receiverTLSConfig(types.ReceiverTLSConfig{
+		CertFile: filepath.Join(dir, leafCertFile),
+		KeyFile:  filepath.Join(dir, leafKeyFile),
+	}, "")
+	if err != nil {
+		t.Fatalf("Expected the supplied certificate to load, got %v", err)
+	}
+	server := startTLSServer(t, tlsConfig)
+
+	res, err := tlsClient(t, filepath.Join(dir, caCertFile)).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the supplied certificate to be served, got %v", err)
+	}
+	res.Body.Close()
+	if served := res.TLS.PeerCertificates[0]; !served.Equal(readCertificate(t, filepath.Join(dir, leafCertFile))) {
+		t.Errorf("Expected the supplied certificate, got %s", served.Subject)
+	}
+
+	if _, err := receiverTLSConfig(types.ReceiverTLSConfig{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: filepath.Join(dir, leafKeyFile)}, ""); err == nil {
+		t.Errorf("Expected a missing certificate to fail")
+	}
+}
+
+func TestReceiverTLSConfig_GeneratedCertificates(t *testing.T) {
+	dir := t.TempDir()
+	cfg := types.ReceiverTLSConfig{GenerateDir: dir}
+
+	tlsConfig, err := receiverTLSConfig(cfg, "https://hooks.example.test:8443/callbacks")
+	if err != nil {
+		t.Fatalf("Expected certificates to be generated, got %v", err)
+	}
+	leaf := readCertificate(t, filepath.Join(dir, leafCertFile))
+	if !slices.Contains(leaf.DNSNames, "hooks.example.test") || !slices.Contains(leaf.DNSNames, "localhost") {
+		t.Errorf("Expected the public url host in the SANs, got %v", leaf.DNSNames)
+	}
+
+	server := startTLSServer(t, tlsConfig)
+	res, err := tlsClient(t, filepath.Join(dir, caCertFile)).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the generated CA to be trusted, got %v", err)
+	}
+	res.Body.Close()
+
+	// a second run keeps the CA so it only needs to be trusted once
+	ca, _ := os.ReadFile(filepath.Join(dir, caCertFile))
+	if _, err := receiverTLSConfig(cfg, ""); err != nil {
+		t.Fatal(err)
+	}
+	reused, _ := os.ReadFile(filepath.Join(dir, caCertFile))
+	if !bytes.Equal(ca, reused) {
+		t.Errorf("Expected the CA to be reused across runs")
+	}
+	if renewed := readCertificate(t, filepath.Join(dir, leafCertFile)); renewed.CheckSignatureFrom(readCertificate(t, filepath.Join(dir, caCertFile))) != nil {
+		t.Errorf("Expected the new leaf to be signed by the kept CA")
+	}
+}
+
+func TestReceiverTLSConfig_RequireClientCert(t *testing.T) {
+	dir := t.TempDir()
+	tlsConfig, err := receiverTLSConfig(types.ReceiverTLSConfig{GenerateDir: dir, RequireClientCert: true}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := startTLSServer(t, tlsConfig)
+	caFile := filepath.Join(dir, caCertFile)
+
+	if res, err := tlsClient(t, caFile).Get(server.URL); err == nil {
+		res.Body.Close()
+		t.Errorf("Expected a client without a certificate to be rejected")
+	}
+
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, clientCertFile), filepath.Join(dir, clientKeyFile))
+	if err != nil {
+		t.Fatalf("Expected a client certificate to be generated, got %v", err)
+	}
+	res, err := tlsClient(t, caFile, clientCert).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the generated client certificate to be accepted, got %v", err)
+	}
+	res.Body.Close()
+}
+
+func TestReceiverTLSConfig_SuppliedCertNeedsClientCA(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := generateCertificates(types.ReceiverTLSConfig{GenerateDir: dir}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := receiverTLSConfig(types.ReceiverTLSConfig{
+		CertFile:          filepath.Join(dir, leafCertFile),
+		KeyFile:           filepath.Join(dir, leafKeyFile),
+		RequireClientCert: true,
+	}, "")
+	if err == nil || !strings.Contains(err.Error(), "clientCaFile is required") {
+		t.Errorf("Expected clientCaFile to be required, got %v", err)
+	}
+}
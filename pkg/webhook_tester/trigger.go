@@ -0,0 +1,170 @@
+package webhook_tester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const TriggerHTTP = "http"
+
+// TriggerMessage is the templated trigger for one correlation id.
+type TriggerMessage struct {
+	CorrelationID string
+	Header        http.Header
+	Body          map[string]any
+}
+
+// Payload is the json encoded body.
+func (m *TriggerMessage) Payload() ([]byte, error) {
+	return json.Marshal(m.Body)
+}
+
+// TriggerResponse is what a transport answered straight away, if anything.
+type TriggerResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Trigger sends the messages that start the workflow under test.
+type Trigger interface {
+	// Target describes where messages go, eg. a url or a topic.
+	Target() string
+	// Fire sends msg. Transports without a synchronous answer return a nil
+	// response.
+	Fire(ctx context.Context, msg *TriggerMessage) (*TriggerResponse, error)
+	Close() error
+}
+
+// TriggerFactory builds a trigger from the run's config.
+type TriggerFactory func(config *types.InputConfig) (Trigger, error)
+
+var (
+	triggersLock sync.RWMutex
+	triggers     = map[string]TriggerFactory{}
+)
+
+// RegisterTrigger makes a transport available as test.trigger name,
+// registering a name twice replaces the earlier transport.
+func RegisterTrigger(name string, factory TriggerFactory) {
+	triggersLock.Lock()
+	defer triggersLock.Unlock()
+	triggers[name] = factory
+}
+
+// Triggers lists the names of every registered transport.
+func Triggers() []string {
+	triggersLock.RLock()
+	defer triggersLock.RUnlock()
+
+	names := make([]string, 0, len(triggers))
+	for name := range triggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterTrigger(TriggerHTTP, newHTTPTrigger)
+}
+
+// triggerType resolves which transport config asks for.
+func triggerType(config *types.InputConfig) string {
+	if config.Test.Trigger != "" {
+		return config.Test.Trigger
+	}
+	return TriggerHTTP
+}
+
+func triggerFactory(config *types.InputConfig) (TriggerFactory, error) {
+	name := triggerType(config)
+
+	triggersLock.RLock()
+	factory, found := triggers[name]
+	triggersLock.RUnlock()
+	if !found {
+		return nil, errors.New("Unknown trigger: " + name + ", expected one of " + strings.Join(Triggers(), ", "))
+	}
+	return factory, nil
+}
+
+// newTrigger builds the transport config asks for.
+func newTrigger(config *types.InputConfig) (Trigger, error) {
+	factory, err := triggerFactory(config)
+	if err != nil {
+		return nil, err
+	}
+	return factory(config)
+}
+
+// httpTrigger posts the message to the api under test. Answers outside
+// 2xx fail the trigger, the response is still returned.
+type httpTrigger struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPTrigger(config *types.InputConfig) (Trigger, error) {
+	return &httpTrigger{url: config.Test.URL, client: http.DefaultClient}, nil
+}
+
+func (t *httpTrigger) Target() string {
+	return t.url
+}
+
+func (t *httpTrigger) Fire(ctx context.Context, msg *TriggerMessage) (*TriggerResponse, error) {
+	payload, err := msg.Payload()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = msg.Header.Clone()
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	response := &TriggerResponse{Status: res.StatusCode, Header: res.Header, Body: resBody}
+	if err == nil && (res.StatusCode < 200 || res.StatusCode >= 300) {
+		err = errors.New("Target responded with " + res.Status)
+	}
+	return response, err
+}
+
+func (t *httpTrigger) Close() error {
+	return nil
+}
+
+// UseTrigger makes FireRequests send requests through trigger instead of
+// the one named by test.trigger.
+func (wt *DefaultWebhookTester) UseTrigger(trigger Trigger) {
+	wt.internal.trigger = trigger
+}
+
+// Close releases the trigger's connections and finishes the archive file.
+func (wt *DefaultWebhookTester) Close() error {
+	var errs []error
+	if wt.internal.trigger != nil {
+		errs = append(errs, wt.internal.trigger.Close())
+	}
+	if wt.internal.archive != nil {
+		errs = append(errs, wt.internal.archive.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,52 @@
+package webhook_tester
+
+import (
+	"context"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/broker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+func init() {
+	RegisterTrigger(broker.KindNATS, newBrokerTrigger)
+	RegisterTrigger(broker.KindKafka, newBrokerTrigger)
+	RegisterTrigger(broker.KindRedis, newBrokerTrigger)
+	RegisterTrigger(broker.KindAMQP, newBrokerTrigger)
+}
+
+// brokerTrigger publishes the message to a broker topic and sends the
+// values of header injectors as message headers.
+type brokerTrigger struct {
+	cfg       types.BrokerConfig
+	publisher broker.Publisher
+}
+
+func newBrokerTrigger(config *types.InputConfig) (Trigger, error) {
+	cfg := config.Test.Broker
+	cfg.Kind = triggerType(config)
+
+	publisher, err := broker.NewPublisher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &brokerTrigger{cfg: cfg, publisher: publisher}, nil
+}
+
+func (t *brokerTrigger) Target() string {
+	if t.cfg.Exchange != "" {
+		return t.cfg.Kind + "://" + t.cfg.Exchange + "/" + t.cfg.Topic
+	}
+	return t.cfg.Kind + "://" + t.cfg.Topic
+}
+
+func (t *brokerTrigger) Fire(ctx context.Context, msg *TriggerMessage) (*TriggerResponse, error) {
+	payload, err := msg.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return nil, t.publisher.Publish(ctx, broker.Message{Header: msg.Header, Body: payload})
+}
+
+func (t *brokerTrigger) Close() error {
+	return t.publisher.Close()
+}
@@ -0,0 +1,120 @@
+package webhook_tester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const TriggerGraphQL = "graphql"
+
+// variablesRoot is the locator root that points into the variables of a
+// graphql operation.
+const variablesRoot = "variables."
+
+func init() {
+	RegisterTrigger(TriggerGraphQL, newGraphQLTrigger)
+}
+
+// graphqlTrigger posts test.body as a graphql operation. Statuses outside
+// 2xx fail the trigger, and so do errors in the response even when the
+// status is 200.
+type graphqlTrigger struct {
+	*httpTrigger
+}
+
+func newGraphQLTrigger(config *types.InputConfig) (Trigger, error) {
+	return &graphqlTrigger{&httpTrigger{url: config.Test.URL, client: http.DefaultClient}}, nil
+}
+
+func (t *graphqlTrigger) Fire(ctx context.Context, msg *TriggerMessage) (*TriggerResponse, error) {
+	if msg.Header.Get("Content-Type") == "" {
+		msg.Header.Set("Content-Type", "application/json")
+	}
+	// answers outside 2xx already fail the http trigger
+	res, err := t.httpTrigger.Fire(ctx, msg)
+	if err != nil {
+		return res, err
+	}
+
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(res.Body, &result); err != nil {
+		return res, errors.New("Invalid GraphQL response: " + err.Error())
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+		return res, fmt.Errorf("GraphQL errors: %s", strings.Join(messages, "; "))
+	}
+	return res, nil
+}
+
+// graphqlRequest is the envelope posted for a graphql operation, a fresh
+// copy of the variables every time so injectors can't leak between
+// requests.
+func graphqlRequest(test types.TestConfig) map[string]any {
+	request := map[string]any{
+		"query":     test.Body,
+		"variables": map[string]any{},
+	}
+	if variables, ok := normalizeYAML(test.GraphQL.Variables).(map[string]any); ok {
+		request["variables"] = variables
+	}
+	if test.GraphQL.OperationName != "" {
+		request["operationName"] = test.GraphQL.OperationName
+	}
+	return request
+}
+
+// normalizeYAML copies v turning the map[any]any yaml decodes nested
+// mappings into map[string]any, so it can be encoded as json.
+func normalizeYAML(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalizeYAML(value)
+		}
+		return m
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[key] = normalizeYAML(value)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, value := range v {
+			s[i] = normalizeYAML(value)
+		}
+		return s
+	}
+	return v
+}
+
+// resolveVariableLocators points variables.-rooted injectors and request
+// comparisons at the variables inside the graphql envelope.
+func resolveVariableLocators(test *types.TestConfig) {
+	resolve := func(path string) string {
+		if strings.HasPrefix(path, variablesRoot) {
+			return "body." + path
+		}
+		return path
+	}
+	test.Injectors.CorrelationIDInjector.Path = resolve(test.Injectors.CorrelationIDInjector.Path)
+	test.Injectors.ReplyPathInjector.Path = resolve(test.Injectors.ReplyPathInjector.Path)
+	for i := range test.Expect {
+		test.Expect[i].EqualsRequest = resolve(test.Expect[i].EqualsRequest)
+	}
+}
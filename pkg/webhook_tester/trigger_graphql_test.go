@@ -0,0 +1,182 @@
+package webhook_tester
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/tracker"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+const graphqlTestConfig = `
+test:
+  name: start-job
+  tags:
+    env: test
+  trigger: graphql
+  body: |
+    mutation Start($input: StartInput!) {
+      startJob(input: $input) { id }
+    }
+  graphql:
+    operationName: Start
+    variables:
+      input:
+        kind: resize
+        tags: [a, b]
+  injectors:
+    correlationIdInjector:
+      path: variables.input.uniqueId
+    replyPathInjector:
+      path: headers.reply-to
+  pickers:
+    correlationPicker:
+      path: body.uniqueId
+  timeout: 5
+receiver:
+  listen: 127.0.0.1:0
+run:
+  iterations: 5
+`
+
+// startGraphQLService stands in for a graphql api that starts a job and
+// calls back reply-to with its id. Jobs of kind fail are refused with a
+// graphql error, jobs of kind down with a 500.
+func startGraphQLService(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query         string `json:"query"`
+			OperationName string `json:"operationName"`
+			Variables     struct {
+				Input struct {
+					Kind     string   `json:"kind"`
+					Tags     []string `json:"tags"`
+					UniqueID string   `json:"uniqueId"`
+				} `json:"input"`
+			} `json:"variables"`
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a json request, got %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Invalid graphql request: %v", err)
+			return
+		}
+		if !strings.Contains(req.Query, "startJob") || req.OperationName != "Start" || len(req.Variables.Input.Tags) != 2 {
+			t.Errorf("Unexpected graphql request %+v", req)
+		}
+
+		input := req.Variables.Input
+		if input.Kind == "down" {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if input.Kind == "fail" {
+			w.Write([]byte(`{"data": null, "errors": [{"message": "kind not allowed"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"startJob": {"id": "` + input.UniqueID + `"}}}`))
+
+		go func() {
+			body, _ := json.Marshal(map[string]string{"uniqueId": input.UniqueID})
+			res, err := http.Post(r.Header.Get("Reply-To"), "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Errorf("Failed to call back: %v", err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func runGraphQLTrigger(t *testing.T, config *types.InputConfig) (*DefaultWebhookTester, error) {
+	wt := NewDefaultWebhookTester(config)
+	if err := wt.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	t.Cleanup(stop)
+	t.Cleanup(func() { wt.Close() })
+
+	if err := wt.FireRequests(); err != nil {
+		t.Fatalf("FireRequests failed: %v", err)
+	}
+	return wt, wt.WaitForResults()
+}
+
+func loadGraphQLConfig(t *testing.T) *types.InputConfig {
+	var config types.InputConfig
+	if err := yaml.Unmarshal([]byte(graphqlTestConfig), &config); err != nil {
+		t.Fatal(err)
+	}
+	config.Test.URL = startGraphQLService(t)
+	return &config
+}
+
+func TestGraphQLTrigger(t *testing.T) {
+	wt, err := runGraphQLTrigger(t, loadGraphQLConfig(t))
+	if err != nil {
+		t.Fatalf("Expected every operation to be called back, got %v", err)
+	}
+	// callbacks may beat the responses, wait for those to be recorded too
+	wt.WaitForRequests()
+	for id, record := range wt.Records() {
+		if record.Outcome() != tracker.OutcomeOK || record.StatusCode != http.StatusOK || record.Scenario != "start-job" || record.Tags["env"] != "test" {
+			t.Errorf("%s: unexpected record %+v", id, record)
+		}
+		if record.RequestBytes == 0 || record.ResponseBytes == 0 || record.CallbackBytes == 0 || record.SyncLatency() <= 0 {
+			t.Errorf("%s: expected sizes and sync latency to be recorded, got %+v", id, record)
+		}
+	}
+}
+
+func TestGraphQLTrigger_ErrorsFailTrigger(t *testing.T) {
+	config := loadGraphQLConfig(t)
+	config.Test.GraphQL.Variables["input"].(map[any]any)["kind"] = "fail"
+
+	wt, err := runGraphQLTrigger(t, config)
+	if err != nil {
+		t.Fatalf("Expected failed operations not to be waited for, got %v", err)
+	}
+	wt.WaitForRequests()
+	records := wt.Records()
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 records, got %d", len(records))
+	}
+	for id, record := range records {
+		if record.Outcome() != tracker.OutcomeTriggerFailed || record.Error != "trigger failed: GraphQL errors: kind not allowed" {
+			t.Errorf("%s: expected the graphql error to fail the trigger, got %s: %q", id, record.Outcome(), record.Error)
+		}
+		// the status is kept even though the operation failed
+		if record.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected the 200 to be recorded, got %d", id, record.StatusCode)
+		}
+	}
+}
+
+func TestGraphQLTrigger_ErrorStatusFailsTrigger(t *testing.T) {
+	config := loadGraphQLConfig(t)
+	config.Test.GraphQL.Variables["input"].(map[any]any)["kind"] = "down"
+
+	wt, err := runGraphQLTrigger(t, config)
+	if err != nil {
+		t.Fatalf("Expected failed operations not to be waited for, got %v", err)
+	}
+	wt.WaitForRequests()
+	for id, record := range wt.Records() {
+		if record.Outcome() != tracker.OutcomeTriggerFailed || record.StatusCode != http.StatusInternalServerError {
+			t.Errorf("%s: expected the 500 to fail the trigger, got %s with %d", id, record.Outcome(), record.StatusCode)
+		}
+	}
+}
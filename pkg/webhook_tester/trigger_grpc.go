@@ -0,0 +1,64 @@
+package webhook_tester
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/grpcclient"
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+)
+
+const TriggerGRPC = "grpc"
+
+func init() {
+	RegisterTrigger(TriggerGRPC, newGRPCTrigger)
+}
+
+// grpcTrigger calls a unary method with the body as its request message,
+// headers travel as metadata. Anything but an OK status fails the trigger.
+type grpcTrigger struct {
+	cfg    types.GRPCConfig
+	client *grpcclient.Client
+}
+
+func newGRPCTrigger(config *types.InputConfig) (Trigger, error) {
+	client, err := grpcclient.Dial(config.Test.GRPC)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcTrigger{cfg: config.Test.GRPC, client: client}, nil
+}
+
+func (t *grpcTrigger) Target() string {
+	return "grpc://" + t.cfg.Address + t.client.Method()
+}
+
+func (t *grpcTrigger) Fire(ctx context.Context, msg *TriggerMessage) (*TriggerResponse, error) {
+	payload, err := msg.Payload()
+	if err != nil {
+		return nil, err
+	}
+	res, err := t.client.Invoke(ctx, msg.Header, payload)
+	if res == nil {
+		return nil, err
+	}
+
+	// The status is reported the way it travels, as trailers
+	header := http.Header{}
+	for key, values := range res.Header {
+		header[http.CanonicalHeaderKey(key)] = values
+	}
+	for key, values := range res.Trailer {
+		header[http.CanonicalHeaderKey(key)] = values
+	}
+	header.Set("Grpc-Status", strconv.Itoa(int(res.Code)))
+	if res.Message != "" {
+		header.Set("Grpc-Message", res.Message)
+	}
+	return &TriggerResponse{Header: header, Body: res.Body}, err
+}
+
+func (t *grpcTrigger) Close() error {
+	return t.client.Close()
+}
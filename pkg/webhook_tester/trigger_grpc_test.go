@@ -0,0 +1,210 @@
+package webhook_tester
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sarkarshuvojit/webhook-load-tester/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// jobsProto is what protoc makes of
+//
+//	package jobs.v1;
+//	message StartRequest { string unique_id = 1; string kind = 2; }
+//	message StartReply { string job_id = 1; }
+//	service Jobs { rpc Start(StartRequest) returns (StartReply); }
+func jobsProto() *descriptorpb.FileDescriptorProto {
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("jobs/v1/jobs.proto"),
+		Package: proto.String("jobs.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("StartRequest"), Field: []*descriptorpb.FieldDescriptorProto{field("unique_id", 1), field("kind", 2)}},
+			{Name: proto.String("StartReply"), Field: []*descriptorpb.FieldDescriptorProto{field("job_id", 1)}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Jobs"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Start"),
+				InputType:  proto.String(".jobs.v1.StartRequest"),
+				OutputType: proto.String(".jobs.v1.StartReply"),
+			}},
+		}},
+	}
+}
+
+// startJobsService stands in for a grpc service that calls back the
+// reply-to metadata with the request, or fails every call with failWith.
+// It returns the address the service listens on.
+func startJobsService(t *testing.T, failWith codes.Code) string {
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{jobsProto()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, _ := files.FindDescriptorByName("jobs.v1.Jobs")
+	method := desc.(protoreflect.ServiceDescriptor).Methods().ByName("Start")
+
+	start := func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		req := dynamicpb.NewMessage(method.Input())
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if failWith != codes.OK {
+			return nil, status.Error(failWith, "no capacity")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		body, _ := protojson.Marshal(req)
+		go func() {
+			res, err := http.Post(md.Get("reply-to")[0], "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Errorf("Failed to call back: %v", err)
+				return
+			}
+			res.Body.Close()
+		}()
+
+		reply := dynamicpb.NewMessage(method.Output())
+		reply.Set(method.Output().Fields().ByName("job_id"), protoreflect.ValueOfString("job-1"))
+		return reply, nil
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "jobs.v1.Jobs",
+		HandlerType: (*any)(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: "Start", Handler: start}},
+	}, struct{}{})
+	rpb.RegisterServerReflectionServer(server, reflection.NewServer(reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: files,
+		ExtensionResolver:  new(protoregistry.Types),
+	}))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func grpcTestConfig(cfg types.GRPCConfig) *types.InputConfig {
+	var config types.InputConfig
+	config.Test.Trigger = TriggerGRPC
+	config.Test.GRPC = cfg
+	config.Test.Body = `{"kind": "resize"}`
+	config.Test.Injectors.ReplyPathInjector.Path = "headers.reply-to"
+	config.Test.Injectors.CorrelationIDInjector.Path = "body.uniqueId"
+	config.Test.Pickers.CorrelationPicker.Path = "body.uniqueId"
+	config.Test.Timeout = 5
+	config.Receiver.Listen = "127.0.0.1:0"
+	config.Run.Iterations = 5
+	config.Run.DurationSeconds = 0
+	return &config
+}
+
+func runGRPCTrigger(t *testing.T, config *types.InputConfig) (*DefaultWebhookTester, error) {
+	wt := NewDefaultWebhookTester(config)
+	if err := wt.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	stop, err := wt.StartReceiver()
+	if err != nil {
+		t.Fatalf("StartReceiver failed: %v", err)
+	}
+	t.Cleanup(stop)
+	t.Cleanup(func() { wt.Close() })
+
+	if err := wt.FireRequests(); err != nil {
+		t.Fatalf("FireRequests failed: %v", err)
+	}
+	return wt, wt.WaitForResults()
+}
+
+func TestGRPCTrigger_Reflection(t *testing.T) {
+	addr := startJobsService(t, codes.OK)
+
+	wt, err := runGRPCTrigger(t, grpcTestConfig(types.GRPCConfig{Address: addr, Method: "jobs.v1.Jobs/Start"}))
+	if err != nil {
+		t.Fatalf("Expected every call to be called back, got %v", err)
+	}
+	for id, record := range wt.Records() {
+		if record.Error != "" || record.EndTime.Before(record.StartTime) {
+			t.Errorf("%s: unexpected record %+v", id, record)
+		}
+	}
+}
+
+func TestGRPCTrigger_DescriptorSet(t *testing.T) {
+	addr := startJobsService(t, codes.OK)
+
+	raw, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{jobsProto()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jobs.protoset")
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := grpcTestConfig(types.GRPCConfig{Address: addr, Method: "/jobs.v1.Jobs/Start", DescriptorSet: path})
+	if _, err := runGRPCTrigger(t, config); err != nil {
+		t.Fatalf("Expected every call to be called back, got %v", err)
+	}
+}
+
+func TestGRPCTrigger_StatusFailsTrigger(t *testing.T) {
+	addr := startJobsService(t, codes.ResourceExhausted)
+
+	config := grpcTestConfig(types.GRPCConfig{Address: addr, Method: "jobs.v1.Jobs/Start"})
+	config.Test.Timeout = 1
+	wt, _ := runGRPCTrigger(t, config)
+	wt.WaitForRequests()
+	records := wt.Records()
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 records, got %d", len(records))
+	}
+	for id, record := range records {
+		if !strings.Contains(record.Error, "trigger failed") || !strings.Contains(record.Error, "ResourceExhausted") {
+			t.Errorf("%s: expected the status to fail the trigger, got %q", id, record.Error)
+		}
+	}
+}
+
+func TestGRPCTrigger_UnknownMethod(t *testing.T) {
+	addr := startJobsService(t, codes.OK)
+
+	wt := NewDefaultWebhookTester(grpcTestConfig(types.GRPCConfig{Address: addr, Method: "jobs.v1.Jobs/Stop"}))
+	if _, err := newTrigger(wt.config); err == nil || !strings.Contains(err.Error(), "Grpc method not found") {
+		t.Errorf("Expected the missing method to be reported, got %v", err)
+	}
+}